@@ -0,0 +1,537 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/golicense/module"
+)
+
+// errNoBinaries is returned by readArchive and readDir if there are no
+// Go binaries with build information.
+var errNoBinaries = errors.New("no Go binaries with build information found")
+
+// archiveKind is the kind of archive an input is.
+type archiveKind int
+
+const (
+	archiveNone archiveKind = iota
+	archiveTar
+	archiveGzip // gzip-compressed tar
+	archiveZip
+)
+
+// sniffArchive returns the kind of archive that starts with header, which
+// should be at least the first 512 bytes of the file.
+func sniffArchive(header []byte) archiveKind {
+	switch {
+	case bytes.HasPrefix(header, []byte("PK\x03\x04")):
+		return archiveZip
+
+	case bytes.HasPrefix(header, []byte{0x1f, 0x8b}):
+		return archiveGzip
+
+	case len(header) >= 262 && string(header[257:262]) == "ustar":
+		return archiveTar
+	}
+
+	return archiveNone
+}
+
+// readHeader returns the first 512 bytes of the file at path, or fewer if
+// the file is smaller, for sniffing the kind of file it is.
+func readHeader(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	header := make([]byte, 512)
+	n, err := io.ReadFull(f, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+
+	return header[:n], nil
+}
+
+// readArchive reads the Go binaries with build information in a tar,
+// gzip-compressed tar or zip archive. Each binary is a separate input
+// with a path such as "archive.tar:bin/program". Files that aren't Go
+// binaries are skipped.
+//
+// Tar archives within a tar archive are read as the layers of container
+// images, such as the output of "docker save" or an OCI image layout.
+// The layers are applied in the order listed by the image manifest,
+// including deleted files, and the paths of binaries are the absolute
+// paths within the image, such as "image.tar:/usr/bin/program". If the
+// archive has several images, the path includes the image, such as
+// "image.tar:repo:tag:/usr/bin/program".
+func readArchive(path string, kind archiveKind) ([]*input, error) {
+	a := &archive{
+		Path:   path,
+		Files:  newArchiveLayer(),
+		Layers: make(map[string]*archiveLayer),
+		JSON:   make(map[string][]byte),
+	}
+
+	var err error
+	if kind == archiveZip {
+		err = a.readZip()
+	} else {
+		err = a.readTar(kind == archiveGzip)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result, noModules := a.inputs()
+	switch {
+	case len(result) > 0:
+		return result, nil
+
+	case noModules:
+		// The same as readExe for a binary without dependencies
+		return nil, errNoModuleInfo
+	}
+
+	return nil, errNoBinaries
+}
+
+// archive is the contents of an archive read by readArchive.
+type archive struct {
+	Path string
+
+	// Files are the files directly in the archive.
+	Files *archiveLayer
+
+	// Layers are the image layers in the archive by their path in the
+	// archive. LayerOrder is the order they were read in.
+	Layers     map[string]*archiveLayer
+	LayerOrder []string
+
+	// JSON are the small JSON files in the archive by their path, which
+	// are the manifests and configuration of images.
+	JSON map[string][]byte
+}
+
+// archiveLayer is the files in a single layer of an image, or in the
+// archive itself.
+type archiveLayer struct {
+	// Files are the regular files by path. This is the build information
+	// of Go binaries and nil for any other file, since those still
+	// replace files in lower layers.
+	Files map[string]*module.BuildInfo
+
+	// Links are the hard links by path to the path of the file they link
+	// to, which is in this layer or a lower layer.
+	Links map[string]string
+
+	// Whiteouts are the paths deleted from lower layers. A path ending
+	// in "/" deletes the contents of the directory.
+	Whiteouts []string
+}
+
+func newArchiveLayer() *archiveLayer {
+	return &archiveLayer{
+		Files: make(map[string]*module.BuildInfo),
+		Links: make(map[string]string),
+	}
+}
+
+// maxArchiveJSON is the maximum size of a JSON file in an archive that is
+// kept for reading image manifests.
+const maxArchiveJSON = 1 << 20
+
+func (a *archive) readZip() error {
+	zr, err := zip.OpenReader(a.Path)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+
+		info, err := readArchiveExe(bufio.NewReader(rc))
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("error reading %s: %s", f.Name, err)
+		}
+
+		a.Files.Files[cleanArchivePath(f.Name)] = info
+	}
+
+	return nil
+}
+
+func (a *archive) readTar(gz bool) error {
+	f, err := os.Open(a.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if gz {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+
+		r = zr
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := cleanArchivePath(h.Name)
+		if h.Typeflag == tar.TypeLink {
+			a.Files.Links[name] = cleanArchivePath(h.Linkname)
+			continue
+		}
+		if !h.FileInfo().Mode().IsRegular() {
+			continue
+		}
+
+		br := bufio.NewReaderSize(tr, 512)
+		header, _ := br.Peek(512)
+		switch kind := sniffArchive(header); {
+		case kind == archiveTar || kind == archiveGzip:
+			l, err := readLayer(br, kind == archiveGzip)
+			if err != nil {
+				return fmt.Errorf("error reading layer %s: %s", name, err)
+			}
+
+			a.Layers[name] = l
+			a.LayerOrder = append(a.LayerOrder, name)
+
+		case strings.HasSuffix(name, ".json") || strings.HasPrefix(name, "blobs/"):
+			// Manifests in OCI layouts are stored as blobs without an
+			// extension, so any small JSON blob is kept.
+			isJSON := len(header) > 0 && (header[0] == '{' || header[0] == '[')
+			if !isJSON || h.Size > maxArchiveJSON {
+				a.Files.Files[name] = nil
+				continue
+			}
+
+			data, err := ioutil.ReadAll(br)
+			if err != nil {
+				return err
+			}
+
+			a.JSON[name] = data
+
+		default:
+			info, err := readArchiveExe(br)
+			if err != nil {
+				return fmt.Errorf("error reading %s: %s", name, err)
+			}
+
+			a.Files.Files[name] = info
+		}
+	}
+}
+
+// readLayer reads the files of an image layer.
+func readLayer(r io.Reader, gz bool) (*archiveLayer, error) {
+	if gz {
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+
+		r = zr
+	}
+
+	result := newArchiveLayer()
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// Deleted files are marked by a ".wh." prefix and deleted
+		// directory contents by an ".wh..wh..opq" file.
+		name := cleanArchivePath(h.Name)
+		dir, base := path.Split(name)
+		switch {
+		case base == ".wh..wh..opq":
+			result.Whiteouts = append(result.Whiteouts, dir)
+			continue
+
+		case strings.HasPrefix(base, ".wh."):
+			result.Whiteouts = append(result.Whiteouts, dir+base[len(".wh."):])
+			continue
+
+		case h.Typeflag == tar.TypeLink:
+			result.Links[name] = cleanArchivePath(h.Linkname)
+			continue
+
+		case !h.FileInfo().Mode().IsRegular():
+			continue
+		}
+
+		info, err := readArchiveExe(bufio.NewReader(tr))
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %s", name, err)
+		}
+
+		result.Files[name] = info
+	}
+}
+
+// readArchiveExe reads the build information of a file in an archive.
+// This returns nil if the file isn't a Go binary with build information.
+//
+// Reading build information needs random access to the binary, so the
+// binary is copied to a temporary file rather than read into memory.
+func readArchiveExe(r *bufio.Reader) (*module.BuildInfo, error) {
+	magic, _ := r.Peek(4)
+	if !isExe(magic) {
+		return nil, nil
+	}
+
+	f, err := ioutil.TempFile("", "golicense-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	if _, err := io.Copy(f, r); err != nil {
+		return nil, err
+	}
+
+	info, err := module.ReadBuildInfoFrom(f)
+	if err != nil {
+		return nil, nil
+	}
+
+	return info, nil
+}
+
+// isExe returns true if magic is the start of an executable format that
+// Go can build: ELF, Mach-O, PE or XCOFF.
+func isExe(magic []byte) bool {
+	for _, prefix := range []string{
+		"\x7fELF",
+		"\xfe\xed\xfa\xce", "\xfe\xed\xfa\xcf", "\xce\xfa\xed\xfe", "\xcf\xfa\xed\xfe",
+		"MZ",
+		"\x01\xdf", "\x01\xf7",
+	} {
+		if bytes.HasPrefix(magic, []byte(prefix)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// cleanArchivePath returns the path of a file in an archive without any
+// leading "/" or "./".
+func cleanArchivePath(p string) string {
+	return strings.TrimPrefix(path.Clean("/"+p), "/")
+}
+
+// inputs returns the Go binaries in the archive, sorted by path. Binaries
+// without dependencies are skipped like readExe rejects them, and
+// noModules is true if any were skipped.
+func (a *archive) inputs() (result []*input, noModules bool) {
+	add := func(prefix string, files map[string]*module.BuildInfo) {
+		for name, info := range files {
+			switch {
+			case info == nil:
+			case !hasModuleInfo(info):
+				noModules = true
+			default:
+				result = append(result, &input{
+					Path:    prefix + name,
+					Modules: info.Deps,
+					Build:   info,
+				})
+			}
+		}
+	}
+
+	files := &archiveImage{Layers: []*archiveLayer{a.Files}}
+	add(a.Path+":", files.Files())
+	images := a.images()
+	for _, img := range images {
+		prefix := a.Path + ":/"
+		if len(images) > 1 {
+			prefix = a.Path + ":" + img.Name + ":/"
+		}
+
+		add(prefix, img.Files())
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+
+	return result, noModules
+}
+
+// archiveImage is a container image in an archive.
+type archiveImage struct {
+	Name   string
+	Layers []*archiveLayer
+}
+
+// Files returns the files of the image after applying the layers in
+// order. Hard links are the same file as the file they link to.
+func (img *archiveImage) Files() map[string]*module.BuildInfo {
+	result := make(map[string]*module.BuildInfo)
+	for _, l := range img.Layers {
+		for _, w := range l.Whiteouts {
+			for name := range result {
+				if name == w || strings.HasPrefix(name, strings.TrimSuffix(w, "/")+"/") {
+					delete(result, name)
+				}
+			}
+		}
+		for name, info := range l.Files {
+			result[name] = info
+		}
+		for name, target := range l.Links {
+			if info, ok := result[target]; ok {
+				result[name] = info
+			}
+		}
+	}
+
+	return result
+}
+
+// images returns the images in the archive with their layers in order.
+// The images are read from a Docker "manifest.json" or an OCI
+// "index.json". If neither is found, the layers are assumed to be a
+// single image in the order they were read.
+func (a *archive) images() []*archiveImage {
+	if len(a.Layers) == 0 {
+		return nil
+	}
+
+	var result []*archiveImage
+	var docker []struct {
+		Config   string
+		RepoTags []string
+		Layers   []string
+	}
+	if err := json.Unmarshal(a.JSON["manifest.json"], &docker); err == nil {
+		for _, m := range docker {
+			img := &archiveImage{Name: m.Config}
+			if len(m.RepoTags) > 0 {
+				img.Name = m.RepoTags[0]
+			}
+			for _, name := range m.Layers {
+				if l, ok := a.Layers[cleanArchivePath(name)]; ok {
+					img.Layers = append(img.Layers, l)
+				}
+			}
+
+			result = append(result, img)
+		}
+	}
+	if len(result) == 0 {
+		result = a.ociImages(a.JSON["index.json"], "")
+	}
+	if len(result) == 0 {
+		img := &archiveImage{}
+		for _, name := range a.LayerOrder {
+			img.Layers = append(img.Layers, a.Layers[name])
+		}
+
+		result = append(result, img)
+	}
+
+	return result
+}
+
+// ociImages returns the images of an OCI image index or manifest. Nested
+// indexes, such as for images with several platforms, are followed. name
+// is the name of the image from the parent index, if any.
+func (a *archive) ociImages(data []byte, name string) []*archiveImage {
+	type descriptor struct {
+		Digest      string
+		Annotations map[string]string
+	}
+
+	var doc struct {
+		Manifests []descriptor
+		Layers    []descriptor
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil
+	}
+
+	blob := func(digest string) string {
+		return "blobs/" + strings.Replace(digest, ":", "/", 1)
+	}
+
+	// A manifest lists layers, an index lists manifests
+	if len(doc.Layers) > 0 {
+		img := &archiveImage{Name: name}
+		for _, d := range doc.Layers {
+			if l, ok := a.Layers[blob(d.Digest)]; ok {
+				img.Layers = append(img.Layers, l)
+			}
+		}
+
+		return []*archiveImage{img}
+	}
+
+	var result []*archiveImage
+	for _, d := range doc.Manifests {
+		// Images without a name of their own, such as the platforms of
+		// a nested index, are named by digest so that they're unique.
+		n := d.Annotations["org.opencontainers.image.ref.name"]
+		switch {
+		case n != "":
+		case name != "" && len(doc.Manifests) > 1:
+			n = name + "@" + d.Digest
+		case name != "":
+			n = name
+		default:
+			n = d.Digest
+		}
+
+		result = append(result, a.ociImages(a.JSON[blob(d.Digest)], n)...)
+	}
+
+	return result
+}
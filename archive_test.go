@@ -0,0 +1,272 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadArchive(t *testing.T) {
+	exe := testExe(t)
+	text := []byte("not a binary")
+
+	// A Docker image with the layers in the archive in a different order
+	// than the manifest. The second layer deletes a file and the contents
+	// of a directory, and hard links a binary of the first layer.
+	layer1 := testTar(t, []testTarEntry{
+		{Name: "usr/bin/a", Data: exe},
+		{Name: "usr/bin/b", Data: exe},
+		{Name: "opt/x/c", Data: exe},
+		{Name: "etc/config", Data: text},
+	})
+	layer2 := testGzip(t, testTar(t, []testTarEntry{
+		{Name: "usr/bin/.wh.b"},
+		{Name: "opt/x/.wh..wh..opq"},
+		{Name: "usr/bin/d", Link: "usr/bin/a"},
+	}))
+	docker := testTar(t, []testTarEntry{
+		{Name: "abc/layer.tar", Data: layer2},
+		{Name: "def/layer.tar", Data: layer1},
+		{Name: "config.json", Data: []byte(`{"architecture": "amd64"}`)},
+		{Name: "manifest.json", Data: []byte(`[{
+			"Config": "config.json",
+			"RepoTags": ["repo:tag"],
+			"Layers": ["def/layer.tar", "abc/layer.tar"]
+		}]`)},
+	})
+
+	// An OCI layout with an index that refers to a nested index of two
+	// platforms, each with their own layers.
+	oci := testTar(t, []testTarEntry{
+		{Name: "oci-layout", Data: []byte(`{"imageLayoutVersion": "1.0.0"}`)},
+		{Name: "index.json", Data: []byte(`{"manifests": [{
+			"digest": "sha256:idx",
+			"annotations": {"org.opencontainers.image.ref.name": "v1"}
+		}]}`)},
+		{Name: "blobs/sha256/idx", Data: []byte(`{"manifests": [
+			{"digest": "sha256:amd64"},
+			{"digest": "sha256:arm64"}
+		]}`)},
+		{Name: "blobs/sha256/amd64", Data: []byte(`{"layers": [{"digest": "sha256:l1"}]}`)},
+		{Name: "blobs/sha256/arm64", Data: []byte(`{"layers": [
+			{"digest": "sha256:l1"},
+			{"digest": "sha256:l2"}
+		]}`)},
+		{Name: "blobs/sha256/l1", Data: testTar(t, []testTarEntry{{Name: "bin/a", Data: exe}})},
+		{Name: "blobs/sha256/l2", Data: testGzip(t, testTar(t, []testTarEntry{
+			{Name: "bin/.wh.a"},
+			{Name: "bin/b", Data: exe},
+		}))},
+	})
+
+	cases := []struct {
+		Name     string
+		File     string
+		Data     []byte
+		Expected []string
+		Err      error
+	}{
+		{
+			"tar",
+			"archive.tar",
+			testTar(t, []testTarEntry{
+				{Name: "./bin/app", Data: exe},
+				{Name: "bin/link", Link: "./bin/app"},
+				{Name: "README", Data: text},
+			}),
+			[]string{":bin/app", ":bin/link"},
+			nil,
+		},
+
+		{
+			"gzip",
+			"archive.tar.gz",
+			testGzip(t, testTar(t, []testTarEntry{{Name: "bin/app", Data: exe}})),
+			[]string{":bin/app"},
+			nil,
+		},
+
+		{
+			"zip",
+			"archive.zip",
+			testZip(t, map[string][]byte{"bin/app": exe, "README": text}),
+			[]string{":bin/app"},
+			nil,
+		},
+
+		{
+			"docker image",
+			"image.tar",
+			docker,
+			[]string{":/usr/bin/a", ":/usr/bin/d"},
+			nil,
+		},
+
+		{
+			"oci nested index",
+			"oci.tar",
+			oci,
+			[]string{":v1@sha256:amd64:/bin/a", ":v1@sha256:arm64:/bin/b"},
+			nil,
+		},
+
+		{
+			"no binaries",
+			"archive.tar",
+			testTar(t, []testTarEntry{{Name: "README", Data: text}}),
+			nil,
+			errNoBinaries,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			path := testFile(t, tt.File, tt.Data)
+			header, err := readHeader(path)
+			require.NoError(t, err)
+
+			ins, err := readArchive(path, sniffArchive(header))
+			require.Equal(t, tt.Err, err)
+
+			var actual []string
+			for _, in := range ins {
+				require.NotEmpty(t, in.Modules)
+				require.NotNil(t, in.Build)
+				actual = append(actual, strings.TrimPrefix(in.Path, path))
+			}
+			require.Equal(t, tt.Expected, actual)
+		})
+	}
+}
+
+func TestReadArchive_noModules(t *testing.T) {
+	// A binary without dependencies is rejected in an archive the same as
+	// on disk.
+	bin := testNoModulesExe(t)
+	_, _, err := readExe(bin)
+	require.Equal(t, errNoModuleInfo, err)
+
+	data, err := ioutil.ReadFile(bin)
+	require.NoError(t, err)
+	path := testFile(t, "archive.tar", testTar(t, []testTarEntry{{Name: "bin/app", Data: data}}))
+	_, err = readArchive(path, archiveTar)
+	require.Equal(t, errNoModuleInfo, err)
+}
+
+func TestSniffArchive(t *testing.T) {
+	require.Equal(t, archiveZip, sniffArchive(testZip(t, map[string][]byte{"a": nil})))
+	require.Equal(t, archiveGzip, sniffArchive(testGzip(t, nil)))
+	require.Equal(t, archiveTar, sniffArchive(testTar(t, []testTarEntry{{Name: "a"}})))
+	require.Equal(t, archiveNone, sniffArchive([]byte("\x7fELF")))
+	require.Equal(t, archiveNone, sniffArchive(nil))
+}
+
+// testTarEntry is a file or hard link in an archive made by testTar.
+type testTarEntry struct {
+	Name string
+	Data []byte
+	Link string // Link is the target of a hard link
+}
+
+func testTar(t *testing.T, entries []testTarEntry) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		h := &tar.Header{
+			Name:     e.Name,
+			Mode:     0755,
+			Size:     int64(len(e.Data)),
+			Typeflag: tar.TypeReg,
+			Format:   tar.FormatPAX,
+		}
+		if e.Link != "" {
+			h.Typeflag, h.Linkname, h.Size = tar.TypeLink, e.Link, 0
+		}
+
+		require.NoError(t, tw.WriteHeader(h))
+		_, err := tw.Write(e.Data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+
+	return buf.Bytes()
+}
+
+func testGzip(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	return buf.Bytes()
+}
+
+func testZip(t *testing.T, files map[string][]byte) []byte {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range names {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write(files[name])
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+
+	return buf.Bytes()
+}
+
+// testFile writes data to a file in a temporary directory.
+func testFile(t *testing.T, name string, data []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, ioutil.WriteFile(path, data, 0644))
+	return path
+}
+
+// testExe returns the contents of the test binary, which is a Go binary
+// with dependencies.
+func testExe(t *testing.T) []byte {
+	path, err := os.Executable()
+	require.NoError(t, err)
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	return data
+}
+
+// testNoModulesExe builds a Go binary without dependencies and returns
+// its path. The test is skipped if the go command isn't available.
+func testNoModulesExe(t *testing.T) string {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, ioutil.WriteFile(
+		filepath.Join(dir, "go.mod"), []byte("module hello\n\ngo 1.18\n"), 0644))
+	require.NoError(t, ioutil.WriteFile(
+		filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644))
+
+	cmd := exec.Command(goBin, "build", "-o", "hello")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	return filepath.Join(dir, "hello")
+}
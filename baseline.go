@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+)
+
+// baseline is the set of modules that the scanned modules are compared
+// against with -diff.
+type baseline struct {
+	Modules []module.Module
+
+	// Results are the license lookups of the modules by moduleKey if the
+	// baseline is a JSON report. This is nil for other baselines, such as
+	// binaries, in which case the licenses must be looked up.
+	Results map[string]lookupResult
+}
+
+// readBaseline reads a baseline. If path has a ".json" extension, it is
+// read as a report written with -out-json, otherwise it is read as an
+// input with readInputs.
+func readBaseline(path string) (*baseline, error) {
+	if filepath.Ext(path) != ".json" {
+		ins, err := readInputs(path)
+		if err != nil {
+			return nil, err
+		}
+
+		result := &baseline{}
+		for _, in := range ins {
+			result.Modules = append(result.Modules, in.Modules...)
+		}
+
+		return result, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var report jsonReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+
+	result := &baseline{Results: make(map[string]lookupResult)}
+	for _, jm := range report.Modules {
+		m := module.Module{
+			Path:    jm.Path,
+			Version: jm.Version,
+			Hash:    jm.Hash,
+		}
+		if r := jm.Replace; r != nil {
+			m.Replace = &module.Module{Path: r.Path, Version: r.Version, Hash: r.Hash}
+		}
+
+		result.Modules = append(result.Modules, m)
+
+		var r lookupResult
+		if jm.License != nil {
+			r.License = jm.License.license()
+		}
+		if jm.Error != "" {
+			r.Err = errors.New(jm.Error)
+		}
+
+		result.Results[moduleKey(&m)] = r
+	}
+
+	return result, nil
+}
+
+// license returns the license that the JSON license was written from.
+func (jl *jsonLicense) license() *license.License {
+	result := &license.License{
+		Name:         jl.Name,
+		SPDX:         jl.SPDX,
+		Warnings:     jl.Warnings,
+		Source:       jl.Source,
+		Confidence:   jl.Confidence,
+		Translations: jl.Translations,
+	}
+	for _, f := range jl.Files {
+		result.Files = append(result.Files, &license.File{
+			Path:       f.Path,
+			Name:       f.Name,
+			SPDX:       f.SPDX,
+			Confidence: f.Confidence,
+		})
+	}
+	for _, c := range jl.Candidates {
+		result.Candidates = append(result.Candidates, &license.Candidate{
+			SPDX:       c.SPDX,
+			Confidence: c.Confidence,
+		})
+	}
+
+	return result
+}
@@ -1,16 +1,23 @@
 package config
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/license/expr"
 )
 
 // Config is the configuration structure for the license checker.
 type Config struct {
 	// Allow and Deny are the list of licenses that are allowed or disallowed,
 	// respectively. The string value here can be either the license name
-	// (case insensitive) or the SPDX ID (case insensitive).
+	// (case insensitive) or the SPDX ID (case insensitive). SPDX IDs may
+	// also be wildcards such as "GPL-*", use the "+" operator such as
+	// "GPL-2.0+" to match that version or later, or be an SPDX expression
+	// such as "MIT OR ISC". The name of a family of licenses such as
+	// "permissive" or "copyleft-strong" matches every license in the
+	// family.
 	//
 	// If a license is found that isn't in either list, then a warning is
 	// emitted. If a license is in both deny and allow, then deny takes
@@ -18,38 +25,229 @@ type Config struct {
 	Allow []string `hcl:"allow,optional"`
 	Deny  []string `hcl:"deny,optional"`
 
+	// Warn is a list of licenses, in the same format as Allow and Deny,
+	// that are highlighted with a warning but don't fail the run. An SPDX
+	// expression is warned about if any of its licenses are in the list.
+	// Licenses that are allowed or denied aren't warned about.
+	Warn []string `hcl:"warn,optional"`
+
+	// FailOn sets which results fail the run. See the FailOn constants.
+	// If this is empty, denied licenses, missing licenses and failed
+	// lookups fail the run, and unknown licenses fail it only if Allow or
+	// Deny is set.
+	FailOn string `hcl:"fail_on,optional"`
+
 	// Override is a map that explicitly sets the license for the given
 	// import path. The key is an import path (exact) and the value is
 	// the name or SPDX ID of the license. Regardless, the value will
 	// be set as both the name and SPDX ID, so SPDX IDs are recommended.
 	Override map[string]string `hcl:"override,optional"`
 
+	// Exceptions accept the licenses of specific modules that would
+	// otherwise not be allowed. See Exception and ModuleAllowed.
+	Exceptions []*Exception `hcl:"exception,block"`
+
 	// Translate is a map that translates one import source into another.
 	// For example, "gopkg.in/(.*)" => "github.com/\1" would translate
 	// gopkg into github (incorrectly, but the example would work).
 	Translate map[string]string `hcl:"translate,optional"`
+
+	// Threshold is the minimum similarity score from 0 to 1 for license
+	// text to match a license when detecting licenses from license files.
+	// If this is zero, the default of 0.90 is used. License text that
+	// doesn't match any license above the threshold is reported with the
+	// closest matches as a warning.
+	Threshold float32 `hcl:"threshold,optional"`
+
+	// GitHub configures license lookups for modules hosted on a GitHub
+	// Enterprise instance. Modules on the public github.com are always
+	// looked up using the public GitHub API.
+	GitHub *Host `hcl:"github,block"`
+
+	// GitLab and Bitbucket configure license lookups for modules hosted
+	// on GitLab and Bitbucket, such as a self-hosted GitLab instance. If
+	// these aren't set, the public gitlab.com and bitbucket.org are used.
+	GitLab    *Host `hcl:"gitlab,block"`
+	Bitbucket *Host `hcl:"bitbucket,block"`
+}
+
+// Values of FailOn. Each value fails the run for the results of the
+// values before it as well.
+const (
+	FailOnDenied  = "denied"  // licenses that are denied
+	FailOnUnknown = "unknown" // licenses that aren't in the allow or deny list
+	FailOnAll     = "all"     // modules without a license and failed lookups
+)
+
+// Host configures the API used to look up the licenses of modules hosted
+// on a source code host.
+type Host struct {
+	// Host is the host name that import paths start with, such as
+	// "gitlab.example.com".
+	Host string `hcl:"host,optional"`
+
+	// URL is the base URL of the API, such as
+	// "https://gitlab.example.com/api/v4".
+	URL string `hcl:"url,optional"`
+}
+
+// Validate returns an error if the configuration is invalid.
+func (c *Config) Validate() error {
+	if c.Threshold < 0 || c.Threshold > 1 {
+		return fmt.Errorf("threshold must be between 0 and 1")
+	}
+
+	switch c.FailOn {
+	case "", FailOnDenied, FailOnUnknown, FailOnAll:
+	default:
+		return fmt.Errorf("fail_on must be %q, %q or %q, got %q",
+			FailOnDenied, FailOnUnknown, FailOnAll, c.FailOn)
+	}
+
+	for _, e := range c.Exceptions {
+		if err := e.validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // Allowed returns the allowed state of a license given the configuration.
+//
+// If the SPDX ID of the license is an SPDX expression such as
+// "MIT OR Apache-2.0" and the expression as a whole isn't in either list,
+// then the expression is evaluated: an OR is allowed if any of its
+// licenses are allowed and an AND is allowed only if all of its licenses
+// are allowed.
+//
+// See match for the entries that Allow and Deny may contain.
 func (c *Config) Allowed(l *license.License) AllowState {
 	if l == nil {
 		return StateDenied // no license is never allowed
 	}
 
-	name := strings.ToLower(l.Name)
-	spdx := strings.ToLower(l.SPDX)
+	e, err := expr.Parse(l.SPDX)
+	if err != nil {
+		// Not an SPDX ID or expression, so match the values as-is
+		return c.state(func(v string) bool {
+			return (l.Name != "" && strings.EqualFold(v, l.Name)) ||
+				(l.SPDX != "" && strings.EqualFold(v, l.SPDX))
+		})
+	}
+
+	if lic, ok := e.(*expr.License); ok {
+		return c.state(func(v string) bool {
+			return match(v, l.Name, lic)
+		})
+	}
+
+	// An expression that is listed as a whole takes priority
+	state := c.state(func(v string) bool {
+		if l.Name != "" && strings.EqualFold(v, l.Name) {
+			return true
+		}
 
+		ve, err := expr.Parse(v)
+		return err == nil && strings.EqualFold(ve.String(), e.String())
+	})
+	if state != StateUnknown {
+		return state
+	}
+
+	return c.allowedExpr(e)
+}
+
+// Warned returns true if the license matches an entry of Warn. The
+// license matches if its name or SPDX ID is listed, or if it is an SPDX
+// expression and any of its licenses match. This doesn't consider Allow
+// and Deny.
+func (c *Config) Warned(l *license.License) bool {
+	if l == nil {
+		return false
+	}
+
+	e, err := expr.Parse(l.SPDX)
+	if err != nil {
+		e = nil
+	}
+
+	for _, v := range c.Warn {
+		if (l.Name != "" && strings.EqualFold(v, l.Name)) ||
+			(l.SPDX != "" && strings.EqualFold(v, l.SPDX)) {
+			return true
+		}
+
+		if e == nil {
+			continue
+		}
+
+		name := ""
+		if _, ok := e.(*expr.License); ok {
+			name = l.Name
+		}
+
+		for _, lic := range expr.Licenses(e) {
+			if match(v, name, lic) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// allowedExpr returns the allowed state of a license expression.
+func (c *Config) allowedExpr(e expr.Expr) AllowState {
+	switch e := e.(type) {
+	case *expr.And:
+		left, right := c.allowedExpr(e.Left), c.allowedExpr(e.Right)
+		switch {
+		case left == StateDenied || right == StateDenied:
+			return StateDenied
+
+		case left == StateAllowed && right == StateAllowed:
+			return StateAllowed
+
+		default:
+			return StateUnknown
+		}
+
+	case *expr.Or:
+		left, right := c.allowedExpr(e.Left), c.allowedExpr(e.Right)
+		switch {
+		case left == StateAllowed || right == StateAllowed:
+			return StateAllowed
+
+		case left == StateDenied && right == StateDenied:
+			return StateDenied
+
+		default:
+			return StateUnknown
+		}
+
+	case *expr.License:
+		return c.state(func(v string) bool {
+			return match(v, "", e)
+		})
+
+	default:
+		return StateUnknown
+	}
+}
+
+// state returns StateDenied if any entry of Deny matches, StateAllowed if
+// any entry of Allow matches, and StateUnknown otherwise.
+func (c *Config) state(match func(string) bool) AllowState {
 	// Deny takes priority
 	for _, v := range c.Deny {
-		v = strings.ToLower(v)
-		if name == v || spdx == v {
+		if match(v) {
 			return StateDenied
 		}
 	}
 
 	for _, v := range c.Allow {
-		v = strings.ToLower(v)
-		if name == v || spdx == v {
+		if match(v) {
 			return StateAllowed
 		}
 	}
@@ -64,3 +262,18 @@ const (
 	StateAllowed
 	StateDenied
 )
+
+// String returns a lowercase, human-friendly name for the state such as
+// "allowed". This is the value used in machine-readable reports.
+func (s AllowState) String() string {
+	switch s {
+	case StateAllowed:
+		return "allowed"
+
+	case StateDenied:
+		return "denied"
+
+	default:
+		return "unknown"
+	}
+}
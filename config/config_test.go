@@ -1,6 +1,7 @@
 package config
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/mitchellh/golicense/license"
@@ -58,6 +59,101 @@ func TestConfigAllowed(t *testing.T) {
 			&license.License{SPDX: "FOO"},
 			StateDenied,
 		},
+
+		{
+			"expression listed",
+			&Config{
+				Allow: []string{"MIT OR GPL-3.0"},
+			},
+			&license.License{SPDX: "MIT OR GPL-3.0"},
+			StateAllowed,
+		},
+
+		{
+			"or with one allowed",
+			&Config{
+				Allow: []string{"MIT"},
+				Deny:  []string{"GPL-3.0"},
+			},
+			&license.License{SPDX: "MIT OR GPL-3.0"},
+			StateAllowed,
+		},
+
+		{
+			"or with none allowed",
+			&Config{
+				Allow: []string{"MIT"},
+			},
+			&license.License{SPDX: "ISC OR GPL-3.0"},
+			StateUnknown,
+		},
+
+		{
+			"or with all denied",
+			&Config{
+				Deny: []string{"ISC", "GPL-3.0"},
+			},
+			&license.License{SPDX: "ISC OR GPL-3.0"},
+			StateDenied,
+		},
+
+		{
+			"and with all allowed",
+			&Config{
+				Allow: []string{"MIT", "ISC"},
+			},
+			&license.License{SPDX: "MIT AND ISC"},
+			StateAllowed,
+		},
+
+		{
+			"and with one unknown",
+			&Config{
+				Allow: []string{"MIT"},
+			},
+			&license.License{SPDX: "MIT AND ISC"},
+			StateUnknown,
+		},
+
+		{
+			"and with one denied",
+			&Config{
+				Allow: []string{"MIT"},
+				Deny:  []string{"GPL-3.0"},
+			},
+			&license.License{SPDX: "MIT AND GPL-3.0"},
+			StateDenied,
+		},
+
+		{
+			"nested expression",
+			&Config{
+				Allow: []string{"MIT", "Apache-2.0"},
+				Deny:  []string{"GPL-3.0"},
+			},
+			&license.License{SPDX: "MIT AND (GPL-3.0 OR Apache-2.0)"},
+			StateAllowed,
+		},
+
+		{
+			"family policy",
+			&Config{
+				Allow: []string{"permissive"},
+				Deny:  []string{"copyleft"},
+			},
+			&license.License{SPDX: "LGPL-2.1-or-later AND BSD-3-Clause"},
+			StateDenied,
+		},
+
+		{
+			"or later policy",
+			&Config{
+				Allow: []string{"MIT"},
+				Deny:  []string{"GPL-2.0+"},
+			},
+			&license.License{Name: "GNU General Public License v3.0 only", SPDX: "GPL-3.0-only"},
+			StateDenied,
+		},
 	}
 
 	for _, tt := range cases {
@@ -67,3 +163,92 @@ func TestConfigAllowed(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigWarned(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Config *Config
+		Lic    *license.License
+		Result bool
+	}{
+		{
+			"empty list",
+			&Config{},
+			&license.License{Name: "MIT", SPDX: "MIT"},
+			false,
+		},
+
+		{
+			"no license",
+			&Config{Warn: []string{"MIT"}},
+			nil,
+			false,
+		},
+
+		{
+			"matching SPDX",
+			&Config{Warn: []string{"mit"}},
+			&license.License{Name: "MIT License", SPDX: "MIT"},
+			true,
+		},
+
+		{
+			"matching name",
+			&Config{Warn: []string{"Some License"}},
+			&license.License{Name: "Some License"},
+			true,
+		},
+
+		{
+			"wildcard",
+			&Config{Warn: []string{"LGPL-*"}},
+			&license.License{SPDX: "LGPL-3.0-only"},
+			true,
+		},
+
+		{
+			"family",
+			&Config{Warn: []string{"copyleft-weak"}},
+			&license.License{SPDX: "MPL-2.0"},
+			true,
+		},
+
+		{
+			"expression with a matching license",
+			&Config{Warn: []string{"MPL-2.0"}},
+			&license.License{SPDX: "MIT OR MPL-2.0"},
+			true,
+		},
+
+		{
+			"no match",
+			&Config{Warn: []string{"MPL-2.0"}},
+			&license.License{SPDX: "MIT AND ISC"},
+			false,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			require.Equal(t, tt.Result, tt.Config.Warned(tt.Lic))
+		})
+	}
+}
+
+func TestAllowStateString(t *testing.T) {
+	require.Equal(t, "unknown", StateUnknown.String())
+	require.Equal(t, "allowed", StateAllowed.String())
+	require.Equal(t, "denied", StateDenied.String())
+}
+
+func TestParse_invalidThreshold(t *testing.T) {
+	_, err := Parse(strings.NewReader("threshold = 90"), "test.hcl", "hcl")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "threshold must be between 0 and 1")
+}
+
+func TestParse_invalidFailOn(t *testing.T) {
+	_, err := Parse(strings.NewReader(`fail_on = "never"`), "test.hcl", "hcl")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "fail_on must be")
+}
@@ -0,0 +1,187 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+)
+
+// ExpiresFormat is the format of the Expires date of an exception.
+const ExpiresFormat = "2006-01-02"
+
+// Exception accepts the license of a specific module that would otherwise
+// not be allowed, such as a denied license that was reviewed. Unlike an
+// override, the license of the module is still detected and reported.
+type Exception struct {
+	// Path is the exact import path of the module. For modules at major
+	// version 2 or above this may include the major version suffix such as
+	// "/v2" or not, see module.Module.ModulePaths.
+	Path string `hcl:"path,label"`
+
+	// License, if set, is the license that is accepted for the module,
+	// in the same format as an Allow entry. If the license of the module
+	// doesn't match, such as if the module changes licenses, the exception
+	// doesn't apply. If this is empty then any license, including no
+	// license, is accepted.
+	License string `hcl:"license,optional"`
+
+	// Reason is the justification for the exception, for auditing.
+	Reason string `hcl:"reason"`
+
+	// Expires, if set, is the date in the format "YYYY-MM-DD" at which the
+	// exception no longer applies.
+	Expires string `hcl:"expires,optional"`
+
+	// Versions, if set, is the comma-separated list of version constraints
+	// that the module version must match for the exception to apply, such
+	// as ">= v1.2.0, < v2.0.0". The operators are =, !=, <, <=, > and >=.
+	// A version without an operator must match exactly.
+	Versions string `hcl:"versions,optional"`
+}
+
+// Expired returns true if the exception is expired at the given time.
+// The exception expires at the start of the Expires date in UTC.
+func (e *Exception) Expired(t time.Time) bool {
+	if e.Expires == "" {
+		return false
+	}
+
+	expires, err := time.Parse(ExpiresFormat, e.Expires)
+	if err != nil {
+		// Validated when parsing the configuration, so an invalid
+		// date never applies
+		return true
+	}
+
+	return !t.Before(expires)
+}
+
+// Matches returns true if the exception applies to the module and its
+// license, regardless of whether the exception is expired.
+func (e *Exception) Matches(m *module.Module, l *license.License) bool {
+	// The major version suffix is stripped from module paths, so the
+	// path is compared against the paths with and without it.
+	matched := false
+	for _, p := range m.ModulePaths() {
+		if e.Path == p {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return false
+	}
+
+	if e.Versions != "" {
+		ok, err := matchVersions(e.Versions, m.Version)
+		if err != nil || !ok {
+			return false
+		}
+	}
+
+	if e.License != "" {
+		c := &Config{Allow: []string{e.License}}
+		if l == nil || c.Allowed(l) != StateAllowed {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validate returns an error if the exception is invalid.
+func (e *Exception) validate() error {
+	if strings.TrimSpace(e.Reason) == "" {
+		return fmt.Errorf("exception %q: reason must not be empty", e.Path)
+	}
+
+	if e.Expires != "" {
+		if _, err := time.Parse(ExpiresFormat, e.Expires); err != nil {
+			return fmt.Errorf("exception %q: expires must be a date in the format YYYY-MM-DD", e.Path)
+		}
+	}
+
+	if e.Versions != "" {
+		if _, err := matchVersions(e.Versions, "v0.0.0"); err != nil {
+			return fmt.Errorf("exception %q: %s", e.Path, err)
+		}
+	}
+
+	return nil
+}
+
+// ModuleAllowed returns the allowed state of the license of a module,
+// taking exceptions into account. If a license that isn't allowed has an
+// exception, the state is StateAllowed and the exception is returned. If
+// the exception is expired, it is returned with the original state so that
+// the expiry can be reported.
+func (c *Config) ModuleAllowed(m *module.Module, l *license.License) (AllowState, *Exception) {
+	state := c.Allowed(l)
+	if state == StateAllowed {
+		return state, nil
+	}
+
+	now := time.Now()
+	var expired *Exception
+	for _, e := range c.Exceptions {
+		if !e.Matches(m, l) {
+			continue
+		}
+
+		if !e.Expired(now) {
+			return StateAllowed, e
+		}
+
+		if expired == nil {
+			expired = e
+		}
+	}
+
+	return state, expired
+}
+
+// matchVersions returns true if the version matches all of the
+// comma-separated constraints.
+func matchVersions(constraints, v string) (bool, error) {
+	result := true
+	for _, c := range strings.Split(constraints, ",") {
+		c = strings.TrimSpace(c)
+		op := ""
+		for _, v := range versionOps {
+			if strings.HasPrefix(c, v) {
+				op = v
+				break
+			}
+		}
+
+		target := strings.TrimSpace(strings.TrimPrefix(c, op))
+		if !strings.HasPrefix(target, "v") || strings.ContainsAny(target, " <>=!") {
+			return false, fmt.Errorf("invalid version constraint %q", c)
+		}
+
+		cmp := module.CompareVersions(v, target)
+		switch op {
+		case "", "=", "==":
+			result = result && cmp == 0
+		case "!=":
+			result = result && cmp != 0
+		case "<":
+			result = result && cmp < 0
+		case "<=":
+			result = result && cmp <= 0
+		case ">":
+			result = result && cmp > 0
+		case ">=":
+			result = result && cmp >= 0
+		}
+	}
+
+	return result, nil
+}
+
+// versionOps are the operators of version constraints. Longer operators
+// are first so that they're matched before their prefixes.
+var versionOps = []string{">=", "<=", "!=", "==", ">", "<", "="}
@@ -0,0 +1,245 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigModuleAllowed(t *testing.T) {
+	gpl := &license.License{Name: "GNU General Public License v3.0", SPDX: "GPL-3.0"}
+	mit := &license.License{Name: "MIT License", SPDX: "MIT"}
+
+	cases := []struct {
+		Name      string
+		Exception *Exception
+		Module    module.Module
+		Lic       *license.License
+		Result    AllowState
+		Excepted  bool
+	}{
+		{
+			"no exception",
+			nil,
+			module.Module{Path: "github.com/foo/bar", Version: "v1.0.0"},
+			gpl,
+			StateDenied,
+			false,
+		},
+
+		{
+			"exception",
+			&Exception{Path: "github.com/foo/bar", Reason: "ok"},
+			module.Module{Path: "github.com/foo/bar", Version: "v1.0.0"},
+			gpl,
+			StateAllowed,
+			true,
+		},
+
+		{
+			"allowed license",
+			&Exception{Path: "github.com/foo/bar", Reason: "ok"},
+			module.Module{Path: "github.com/foo/bar", Version: "v1.0.0"},
+			mit,
+			StateAllowed,
+			false,
+		},
+
+		{
+			"major version suffix",
+			&Exception{Path: "github.com/foo/bar/v2", Reason: "ok"},
+			module.Module{Path: "github.com/foo/bar", Version: "v2.1.0"},
+			gpl,
+			StateAllowed,
+			true,
+		},
+
+		{
+			"major version without suffix",
+			&Exception{Path: "github.com/foo/bar", Reason: "ok"},
+			module.Module{Path: "github.com/foo/bar", Version: "v2.1.0"},
+			gpl,
+			StateAllowed,
+			true,
+		},
+
+		{
+			"other major version",
+			&Exception{Path: "github.com/foo/bar/v3", Reason: "ok"},
+			module.Module{Path: "github.com/foo/bar", Version: "v2.1.0"},
+			gpl,
+			StateDenied,
+			false,
+		},
+
+		{
+			"major version suffix before v2",
+			&Exception{Path: "github.com/foo/bar/v2", Reason: "ok"},
+			module.Module{Path: "github.com/foo/bar", Version: "v1.0.0"},
+			gpl,
+			StateDenied,
+			false,
+		},
+
+		{
+			"other module",
+			&Exception{Path: "github.com/foo/bar", Reason: "ok"},
+			module.Module{Path: "github.com/foo/baz", Version: "v1.0.0"},
+			gpl,
+			StateDenied,
+			false,
+		},
+
+		{
+			"missing license",
+			&Exception{Path: "github.com/foo/bar", Reason: "ok"},
+			module.Module{Path: "github.com/foo/bar", Version: "v1.0.0"},
+			nil,
+			StateAllowed,
+			true,
+		},
+
+		{
+			"license matches",
+			&Exception{Path: "github.com/foo/bar", License: "GPL-*", Reason: "ok"},
+			module.Module{Path: "github.com/foo/bar", Version: "v1.0.0"},
+			gpl,
+			StateAllowed,
+			true,
+		},
+
+		{
+			"license changed",
+			&Exception{Path: "github.com/foo/bar", License: "LGPL-3.0", Reason: "ok"},
+			module.Module{Path: "github.com/foo/bar", Version: "v1.0.0"},
+			gpl,
+			StateDenied,
+			false,
+		},
+
+		{
+			"version matches",
+			&Exception{Path: "github.com/foo/bar", Versions: ">= v1.2.0, < v2.0.0", Reason: "ok"},
+			module.Module{Path: "github.com/foo/bar", Version: "v1.5.0"},
+			gpl,
+			StateAllowed,
+			true,
+		},
+
+		{
+			"version doesn't match",
+			&Exception{Path: "github.com/foo/bar", Versions: ">= v1.2.0, < v2.0.0", Reason: "ok"},
+			module.Module{Path: "github.com/foo/bar", Version: "v1.1.0"},
+			gpl,
+			StateDenied,
+			false,
+		},
+
+		{
+			"expired",
+			&Exception{Path: "github.com/foo/bar", Expires: "2001-01-01", Reason: "ok"},
+			module.Module{Path: "github.com/foo/bar", Version: "v1.0.0"},
+			gpl,
+			StateDenied,
+			true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			c := &Config{Allow: []string{"MIT"}, Deny: []string{"GPL-3.0"}}
+			if tt.Exception != nil {
+				c.Exceptions = []*Exception{tt.Exception}
+			}
+
+			state, e := c.ModuleAllowed(&tt.Module, tt.Lic)
+			require.Equal(t, tt.Result, state)
+			if tt.Excepted {
+				require.Equal(t, tt.Exception, e)
+			} else {
+				require.Nil(t, e)
+			}
+		})
+	}
+}
+
+func TestExceptionExpired(t *testing.T) {
+	e := &Exception{Expires: "2027-01-01"}
+	require.False(t, e.Expired(time.Date(2026, 12, 31, 23, 59, 0, 0, time.UTC)))
+	require.True(t, e.Expired(time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)))
+
+	e = &Exception{}
+	require.False(t, e.Expired(time.Now()))
+}
+
+func TestMatchVersions(t *testing.T) {
+	cases := []struct {
+		Constraints string
+		Version     string
+		Expected    bool
+		Err         bool
+	}{
+		{"v1.2.0", "v1.2.0", true, false},
+		{"= v1.2.0", "v1.2.1", false, false},
+		{"!= v1.2.0", "v1.2.1", true, false},
+		{">= v1.2.0", "v1.2.0", true, false},
+		{">v1.2.0", "v1.2.0", false, false},
+		{"<= v1.2.0", "v1.1.9", true, false},
+		{">= v1.2.0, < v2.0.0", "v2.0.0", false, false},
+		{">= v1.2.0, < v2.0.0", "v1.9.0", true, false},
+		{"~> v1.2.0", "", false, true},
+		{">= 1.2.0", "", false, true},
+		{">= v1.2.0 v1.3.0", "", false, true},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Constraints+"/"+tt.Version, func(t *testing.T) {
+			actual, err := matchVersions(tt.Constraints, tt.Version)
+			if tt.Err {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.Expected, actual)
+		})
+	}
+}
+
+func TestParse_invalidException(t *testing.T) {
+	cases := []struct {
+		Name string
+		Src  string
+		Err  string
+	}{
+		{
+			"no reason",
+			"exception \"a\" {\n reason = \"\"\n}",
+			"reason must not be empty",
+		},
+
+		{
+			"invalid expires",
+			"exception \"a\" {\n reason = \"r\"\n expires = \"01/01/2027\"\n}",
+			"expires must be a date",
+		},
+
+		{
+			"invalid versions",
+			"exception \"a\" {\n reason = \"r\"\n versions = \"~> v1.0\"\n}",
+			"invalid version constraint",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.Src), "test.hcl", "hcl")
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.Err)
+		})
+	}
+}
@@ -66,6 +66,10 @@ func parseHCL(r io.Reader, filename string) (*Config, error) {
 		return nil, diag
 	}
 
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
 	return &config, nil
 }
 
@@ -86,5 +90,9 @@ func parseJSON(r io.Reader, filename string) (*Config, error) {
 		return nil, diag
 	}
 
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
 	return &config, nil
 }
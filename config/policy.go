@@ -0,0 +1,160 @@
+package config
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mitchellh/golicense/license/expr"
+)
+
+// families are the names of families of licenses that can be used in the
+// allow and deny lists, mapped to the entries that they are equivalent to.
+var families = map[string][]string{
+	"permissive": {
+		"0BSD", "Apache-*", "Artistic-2.0", "BlueOak-1.0.0", "BSD-*",
+		"BSL-1.0", "CC0-1.0", "CC-BY-3.0", "CC-BY-4.0", "ISC", "MIT",
+		"MIT-0", "NCSA", "PostgreSQL", "PSF-2.0", "Python-2.0", "Unlicense",
+		"UPL-1.0", "WTFPL", "X11", "Zlib",
+	},
+	"copyleft-weak": {
+		"CDDL-*", "CPL-1.0", "EPL-*", "LGPL-*", "MPL-*",
+	},
+	"copyleft-strong": {
+		"AGPL-*", "CC-BY-SA-*", "EUPL-*", "GPL-*", "OSL-*", "SSPL-*",
+	},
+	"copyleft": {
+		"copyleft-weak", "copyleft-strong",
+	},
+}
+
+// match returns true if the allow or deny list entry v matches the license
+// with the given name and SPDX ID. The name may be empty. v may be:
+//
+//   - the name or SPDX ID of the license, case insensitive
+//   - the name of a license family in families
+//   - an SPDX ID containing "*" wildcards, such as "GPL-*"
+//   - an SPDX ID with the "+" operator such as "GPL-2.0+", which matches
+//     that version of the license and any later version
+//   - an SPDX expression, where an OR matches if any of its licenses match
+//
+// Deprecated SPDX IDs are equivalent to their replacements, so "GPL-2.0"
+// matches "GPL-2.0-only" and "GPL-2.0+" matches "GPL-2.0-or-later".
+func match(v, name string, l *expr.License) bool {
+	if name != "" && strings.EqualFold(v, name) {
+		return true
+	}
+
+	if vs, ok := families[strings.ToLower(v)]; ok {
+		for _, v := range vs {
+			if match(v, "", l) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	e, err := expr.Parse(v)
+	if err != nil {
+		return false
+	}
+
+	return matchExpr(e, l)
+}
+
+// matchExpr returns true if the entry expression e matches the license.
+func matchExpr(e expr.Expr, l *expr.License) bool {
+	switch e := e.(type) {
+	case *expr.Or:
+		return matchExpr(e.Left, l) || matchExpr(e.Right, l)
+
+	case *expr.License:
+		// An entry without an exception matches the license with any
+		// exception, since exceptions only grant additional permissions.
+		if e.Exception != "" && !strings.EqualFold(e.Exception, l.Exception) {
+			return false
+		}
+
+		id := normalizeID(l)
+		if strings.Contains(e.ID, "*") {
+			return globRe(e.ID).MatchString(id) ||
+				globRe(e.ID).MatchString(l.ID)
+		}
+
+		if e.OrLater {
+			return orLater(strings.TrimSuffix(e.ID, "-only"), id)
+		}
+
+		return strings.EqualFold(normalizeID(&expr.License{ID: e.ID}), id)
+
+	default:
+		// An AND can never match a single license
+		return false
+	}
+}
+
+// normalizeID returns the SPDX ID of the license with deprecated IDs
+// replaced: "-only" is removed and "+" becomes "-or-later".
+func normalizeID(l *expr.License) string {
+	id := strings.TrimSuffix(l.ID, "-only")
+	if l.OrLater && !strings.HasSuffix(id, "-or-later") {
+		id += "-or-later"
+	}
+
+	return id
+}
+
+// orLater returns true if the SPDX ID id is the same license as base at
+// the same or a later version.
+func orLater(base, id string) bool {
+	bm := versionIDRe.FindStringSubmatch(base)
+	im := versionIDRe.FindStringSubmatch(strings.TrimSuffix(id, "-or-later"))
+	if bm == nil || im == nil {
+		return strings.EqualFold(base, id)
+	}
+	if !strings.EqualFold(bm[1], im[1]) {
+		return false
+	}
+
+	return compareVersions(im[2], bm[2]) >= 0
+}
+
+// compareVersions compares two dotted version numbers such as "2.1",
+// returning -1, 0 or 1.
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// globRe returns a case insensitive regular expression matching the
+// pattern, where "*" matches any characters.
+func globRe(pattern string) *regexp.Regexp {
+	parts := strings.Split(pattern, "*")
+	for i, p := range parts {
+		parts[i] = regexp.QuoteMeta(p)
+	}
+
+	return regexp.MustCompile("(?i)^" + strings.Join(parts, ".*") + "$")
+}
+
+// versionIDRe matches an SPDX ID that ends in a version number, such as
+// "GPL-2.0" or "CC-BY-SA-4.0", capturing the license and the version.
+var versionIDRe = regexp.MustCompile(`^(.+?)-?(\d+(?:\.\d+)*)$`)
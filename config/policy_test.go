@@ -0,0 +1,74 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/mitchellh/golicense/license/expr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	cases := []struct {
+		Entry    string
+		Name     string
+		SPDX     string
+		Expected bool
+	}{
+		{"MIT", "", "MIT", true},
+		{"mit", "", "MIT", true},
+		{"MIT License", "MIT License", "MIT", true},
+		{"MIT", "", "ISC", false},
+
+		// Deprecated IDs
+		{"GPL-2.0", "", "GPL-2.0-only", true},
+		{"GPL-2.0-only", "", "GPL-2.0", true},
+		{"GPL-2.0-or-later", "", "GPL-2.0+", true},
+		{"GPL-2.0", "", "GPL-2.0+", false},
+
+		// Wildcards
+		{"GPL-*", "", "GPL-3.0-only", true},
+		{"GPL-*", "", "GPL-2.0+", true},
+		{"GPL-*", "", "LGPL-2.1", false},
+		{"*GPL-*", "", "AGPL-3.0", true},
+
+		// Or later
+		{"GPL-2.0+", "", "GPL-2.0", true},
+		{"GPL-2.0+", "", "GPL-3.0-only", true},
+		{"GPL-2.0+", "", "GPL-3.0-or-later", true},
+		{"GPL-2.0+", "", "GPL-1.0", false},
+		{"GPL-2.0+", "", "LGPL-3.0", false},
+		{"LGPL-2.1+", "", "LGPL-3.0", true},
+		{"Apache-1.1+", "", "Apache-2.0", true},
+
+		// Exceptions
+		{"GPL-2.0", "", "GPL-2.0 WITH Classpath-exception-2.0", true},
+		{"GPL-2.0 WITH Classpath-exception-2.0", "", "GPL-2.0 WITH Classpath-exception-2.0", true},
+		{"GPL-2.0 WITH Classpath-exception-2.0", "", "GPL-2.0", false},
+
+		// Expressions
+		{"MIT OR ISC", "", "ISC", true},
+		{"MIT OR ISC", "", "BSD-3-Clause", false},
+		{"MIT AND ISC", "", "MIT", false},
+
+		// Families
+		{"permissive", "", "BSD-3-Clause", true},
+		{"permissive", "", "Apache-2.0", true},
+		{"permissive", "", "GPL-3.0", false},
+		{"copyleft-weak", "", "MPL-2.0", true},
+		{"copyleft-strong", "", "AGPL-3.0-only", true},
+		{"copyleft-strong", "", "LGPL-2.1", false},
+		{"Copyleft", "", "LGPL-2.1", true},
+		{"copyleft", "", "MIT", false},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Entry+"/"+tt.SPDX, func(t *testing.T) {
+			e, err := expr.Parse(tt.SPDX)
+			require.NoError(t, err)
+
+			l, ok := e.(*expr.License)
+			require.True(t, ok)
+			require.Equal(t, tt.Expected, match(tt.Entry, tt.Name, l))
+		})
+	}
+}
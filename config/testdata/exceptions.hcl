@@ -0,0 +1,12 @@
+deny = ["GPL-3.0"]
+
+exception "github.com/foo/bar" {
+  license  = "GPL-3.0"
+  reason   = "Only used by an internal tool that is never distributed"
+  expires  = "2027-01-01"
+  versions = ">= v1.2.0, < v2.0.0"
+}
+
+exception "github.com/foo/baz" {
+  reason = "Reviewed by legal"
+}
@@ -0,0 +1,3 @@
+allow   = ["MIT"]
+warn    = ["LGPL-*", "MPL-2.0"]
+fail_on = "unknown"
@@ -0,0 +1,13 @@
+github {
+  host = "github.example.com"
+  url  = "https://github.example.com/api/v3/"
+}
+
+gitlab {
+  host = "gitlab.example.com"
+  url  = "https://gitlab.example.com/api/v4"
+}
+
+bitbucket {
+  url = "https://bitbucket.example.com/2.0"
+}
@@ -0,0 +1,2 @@
+allow     = ["MIT"]
+threshold = 0.85
@@ -1,24 +1,41 @@
 module github.com/mitchellh/golicense
 
-go 1.13
+go 1.18
 
 require (
 	github.com/360EntSecGroup-Skylar/excelize v1.4.0
+	github.com/davecgh/go-spew v1.1.1
+	github.com/fatih/color v1.7.0
+	github.com/google/go-github/v18 v18.2.0
+	github.com/gosuri/uilive v0.0.0-20170323041506-ac356e6e42cd
+	github.com/hashicorp/go-multierror v1.0.0
+	github.com/hashicorp/hcl2 v0.0.0-20181111172936-0467c0c38ca2
+	github.com/mitchellh/go-spdx v0.1.0
+	github.com/rsc/goversion v1.2.0
+	github.com/sebdah/goldie v0.0.0-20180424091453-8784dd1ab561
+	github.com/stretchr/testify v1.2.2
+	golang.org/x/crypto v0.0.0-20190308221718-c2843e01d9a2
+	golang.org/x/oauth2 v0.0.0-20180821212333-d2e6202438be
+	golang.org/x/tools v0.0.0-20190312151545-0bb0c0a6e846
+	gopkg.in/src-d/go-license-detector.v2 v2.0.0-20180510072912-da552ecf050b
+)
+
+require (
+	github.com/agext/levenshtein v1.2.1 // indirect
 	github.com/alcortesm/tgz v0.0.0-20161220082320-9c5fe88206d7 // indirect
 	github.com/anmitsu/go-shlex v0.0.0-20161002113705-648efa622239 // indirect
-	github.com/davecgh/go-spew v1.1.1
+	github.com/apparentlymart/go-textseg v1.0.0 // indirect
 	github.com/dgryski/go-metro v0.0.0-20180109044635-280f6062b5bc // indirect
 	github.com/dgryski/go-minhash v0.0.0-20170608043002-7fe510aff544 // indirect
 	github.com/dgryski/go-spooky v0.0.0-20170606183049-ed3d087f40e2 // indirect
 	github.com/ekzhu/minhash-lsh v0.0.0-20171225071031-5c06ee8586a1 // indirect
 	github.com/emirpasic/gods v1.12.0 // indirect
-	github.com/fatih/color v1.7.0
 	github.com/flynn/go-shlex v0.0.0-20150515145356-3f9db97f8568 // indirect
 	github.com/gliderlabs/ssh v0.2.2 // indirect
-	github.com/google/go-github/v18 v18.2.0
-	github.com/gosuri/uilive v0.0.0-20170323041506-ac356e6e42cd
-	github.com/hashicorp/go-multierror v1.0.0
-	github.com/hashicorp/hcl2 v0.0.0-20181111172936-0467c0c38ca2
+	github.com/google/go-cmp v0.2.0 // indirect
+	github.com/google/go-querystring v1.0.0 // indirect
+	github.com/hashicorp/errwrap v1.0.0 // indirect
+	github.com/hashicorp/go-cleanhttp v0.5.0 // indirect
 	github.com/hhatto/gorst v0.0.0-20181029133204-ca9f730cac5b // indirect
 	github.com/jbenet/go-context v0.0.0-20150711004518-d14ea06fba99 // indirect
 	github.com/jdkato/prose v1.1.0 // indirect
@@ -26,23 +43,25 @@ require (
 	github.com/mattn/go-colorable v0.0.9 // indirect
 	github.com/mattn/go-isatty v0.0.4 // indirect
 	github.com/mitchellh/go-homedir v1.0.0 // indirect
-	github.com/mitchellh/go-spdx v0.1.0
+	github.com/mitchellh/go-wordwrap v0.0.0-20150314170334-ad45545899c7 // indirect
 	github.com/mohae/deepcopy v0.0.0-20170929034955-c48cc78d4826 // indirect
 	github.com/montanaflynn/stats v0.0.0-20180911141734-db72e6cae808 // indirect
 	github.com/neurosnap/sentences v1.0.6 // indirect
 	github.com/pelletier/go-buffruneio v0.2.0 // indirect
 	github.com/pkg/errors v0.8.0 // indirect
-	github.com/rsc/goversion v1.2.0
-	github.com/sebdah/goldie v0.0.0-20180424091453-8784dd1ab561
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/sergi/go-diff v1.0.0 // indirect
 	github.com/shogo82148/go-shuffle v0.0.0-20180218125048-27e6095f230d // indirect
 	github.com/shurcooL/sanitized_anchor_name v0.0.0-20170918181015-86672fcb3f95 // indirect
 	github.com/src-d/gcfg v1.4.0 // indirect
 	github.com/stretchr/objx v0.1.1 // indirect
-	github.com/stretchr/testify v1.2.2
 	github.com/xanzy/ssh-agent v0.2.0 // indirect
-	golang.org/x/crypto v0.0.0-20190308221718-c2843e01d9a2
-	golang.org/x/oauth2 v0.0.0-20180821212333-d2e6202438be
-	golang.org/x/tools v0.0.0-20190312151545-0bb0c0a6e846
+	github.com/zclconf/go-cty v0.0.0-20180815031001-58bb2bc0302a // indirect
+	golang.org/x/exp v0.0.0-20190312203227-4b39c73a6495 // indirect
+	golang.org/x/net v0.0.0-20190311183353-d8887717615a // indirect
+	golang.org/x/sys v0.0.0-20190312061237-fead79001313 // indirect
+	golang.org/x/text v0.3.0 // indirect
+	gonum.org/v1/gonum v0.6.0 // indirect
 	gonum.org/v1/netlib v0.0.0-20191031114514-eccb95939662 // indirect
 	gopkg.in/neurosnap/sentences.v1 v1.0.6 // indirect
 	gopkg.in/russross/blackfriday.v2 v2.0.0 // indirect
@@ -50,7 +69,6 @@ require (
 	gopkg.in/src-d/go-billy.v4 v4.3.0 // indirect
 	gopkg.in/src-d/go-git-fixtures.v3 v3.5.0 // indirect
 	gopkg.in/src-d/go-git.v4 v4.7.0 // indirect
-	gopkg.in/src-d/go-license-detector.v2 v2.0.0-20180510072912-da552ecf050b
 	gopkg.in/src-d/go-siva.v1 v1.3.0 // indirect
 	gopkg.in/warnings.v0 v0.1.2 // indirect
 )
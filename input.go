@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/rsc/goversion/version"
+
+	"github.com/mitchellh/golicense/module"
+)
+
+// errNoModuleInfo is returned by readExe if the binary has no module
+// information.
+var errNoModuleInfo = errors.New("no module information")
+
+// input is the dependencies read from a single input.
+type input struct {
+	Path    string
+	Modules []module.Module
+
+	// Build is the build information if the input is a binary. This is
+	// nil for other inputs and binaries built with toolchains that are
+	// too old to be read with debug/buildinfo.
+	Build *module.BuildInfo
+
+	// Warnings are problems with the input that don't prevent it from
+	// being read, such as a go.mod file that may not list all modules.
+	Warnings []string
+}
+
+// readInputs reads the dependencies of an input, which is one of:
+//
+//   - a binary compiled from Go
+//   - a go.mod file, or a directory containing one. The hashes of the
+//     dependencies are read from the go.sum file next to it, if any.
+//   - a file with the output of "go list -m -json all"
+//   - a tar, gzip-compressed tar or zip archive, or a container image
+//     saved with "docker save", in which case every Go binary in it is a
+//     separate input. See readArchive.
+//   - a directory without a go.mod file, in which case every Go binary in
+//     it or its subdirectories is a separate input. See readDir.
+func readInputs(path string) ([]*input, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() {
+		if _, err := os.Stat(filepath.Join(path, "go.mod")); os.IsNotExist(err) {
+			return readDir(path)
+		}
+	} else {
+		header, err := readHeader(path)
+		if err != nil {
+			return nil, err
+		}
+		if kind := sniffArchive(header); kind != archiveNone {
+			return readArchive(path, kind)
+		}
+	}
+
+	in, err := readModules(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*input{in}, nil
+}
+
+// readDir reads the Go binaries in a directory and its subdirectories,
+// including the binaries in archives. Files that aren't Go binaries with
+// module information, or archives containing them, are skipped.
+func readDir(root string) ([]*input, error) {
+	var result []*input
+	err := filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+		// Files and directories that can't be read, such as ones we
+		// don't have permission for, are skipped like any other file
+		// that isn't a Go binary. Only the root itself must be readable.
+		if err != nil {
+			if path == root {
+				return err
+			}
+
+			return nil
+		}
+		if !fi.Mode().IsRegular() {
+			return nil
+		}
+
+		header, err := readHeader(path)
+		if err != nil {
+			return nil
+		}
+
+		// Any archive or binary that can't be read, such as a gzipped
+		// file that isn't a tar or a binary not built from Go, is
+		// skipped since most files in a directory aren't Go binaries.
+		if kind := sniffArchive(header); kind != archiveNone {
+			ins, err := readArchive(path, kind)
+			if err == nil {
+				result = append(result, ins...)
+			}
+
+			return nil
+		}
+		if !isExe(header) {
+			return nil
+		}
+
+		mods, build, err := readExe(path)
+		if err == nil {
+			result = append(result, &input{Path: path, Modules: mods, Build: build})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, errNoBinaries
+	}
+
+	return result, nil
+}
+
+// readModules reads the dependencies of an input that isn't an archive.
+// See readInputs.
+func readModules(path string) (*input, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() {
+		return readGoMod(path, filepath.Join(path, "go.mod"))
+	}
+	if filepath.Base(path) == "go.mod" {
+		return readGoMod(path, path)
+	}
+
+	// The output of go list is a stream of JSON objects, which a binary
+	// never starts with.
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	if b, err := firstByte(r); err == nil && b == '{' {
+		mods, err := module.ParseGoList(r)
+		if err != nil {
+			return nil, err
+		}
+
+		return &input{Path: path, Modules: mods}, nil
+	}
+
+	mods, build, err := readExe(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &input{Path: path, Modules: mods, Build: build}, nil
+}
+
+// firstByte returns the first byte of r that isn't whitespace, without
+// consuming it.
+func firstByte(r *bufio.Reader) (byte, error) {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+
+		return b, r.UnreadByte()
+	}
+}
+
+// readGoMod reads the dependencies of the input at path from a go.mod
+// file and the go.sum file next to it. go.mod files for Go versions before
+// 1.17 don't list every module, so the input has a warning.
+func readGoMod(inputPath, path string) (*input, error) {
+	mod, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sum, err := ioutil.ReadFile(filepath.Join(filepath.Dir(path), "go.sum"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	mods, err := module.ParseGoMod(mod, sum)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %s", path, err)
+	}
+
+	result := &input{Path: inputPath, Modules: mods}
+	if !module.GoModComplete(mod) {
+		result.Warnings = append(result.Warnings, "go.mod is for a Go version "+
+			"before 1.17, which doesn't list every module that is needed, so "+
+			"some modules may be missing. Use the output of \"go list -m "+
+			"-json all\" instead to check every module.")
+	}
+
+	return result, nil
+}
+
+// hasModuleInfo returns true if the build information of a binary has
+// dependencies. No dependencies means that the binary didn't use Go
+// modules or it could mean that a binary has no dependencies. Either way
+// the binary is rejected since we can't be sure.
+func hasModuleInfo(info *module.BuildInfo) bool {
+	return len(info.Deps) > 0
+}
+
+// readExe reads the dependencies of a binary. Binaries built with
+// toolchains that are too old to be read with debug/buildinfo are read
+// with goversion instead, in which case there is no build information.
+func readExe(path string) ([]module.Module, *module.BuildInfo, error) {
+	if info, err := module.ReadBuildInfo(path); err == nil {
+		if !hasModuleInfo(info) {
+			return nil, nil, errNoModuleInfo
+		}
+
+		return info.Deps, info, nil
+	}
+
+	vsn, err := version.ReadExe(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// ModuleInfo empty means that the binary didn't use Go modules
+	// or it could mean that a binary has no dependencies. Either way
+	// we error since we can't be sure.
+	if vsn.ModuleInfo == "" {
+		return nil, nil, errNoModuleInfo
+	}
+
+	// From the raw module string from the binary, we need to parse this
+	// into structured data with the module information.
+	mods, err := module.ParseExeData(vsn.ModuleInfo)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error parsing dependencies: %s", err)
+	}
+
+	return mods, nil, nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadDir(t *testing.T) {
+	dir := t.TempDir()
+	exe := filepath.Join(dir, "bin", "app")
+	require.NoError(t, os.MkdirAll(filepath.Dir(exe), 0755))
+	require.NoError(t, ioutil.WriteFile(exe, testExe(t), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "README"), []byte("hello"), 0644))
+
+	ins, err := readDir(dir)
+	require.NoError(t, err)
+	require.Len(t, ins, 1)
+	require.Equal(t, exe, ins[0].Path)
+}
+
+func TestReadDir_unreadable(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can read every file")
+	}
+
+	// Files and directories that can't be read are skipped rather than
+	// stopping the walk.
+	dir := t.TempDir()
+	exe := filepath.Join(dir, "app")
+	require.NoError(t, ioutil.WriteFile(exe, testExe(t), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "secret"), []byte("hello"), 0000))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "private"), 0000))
+	defer os.Chmod(filepath.Join(dir, "private"), 0755)
+
+	ins, err := readDir(dir)
+	require.NoError(t, err)
+	require.Len(t, ins, 1)
+	require.Equal(t, exe, ins[0].Path)
+}
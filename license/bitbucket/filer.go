@@ -0,0 +1,68 @@
+package bitbucket
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"path"
+	"strings"
+
+	"gopkg.in/src-d/go-license-detector.v2/licensedb/filer"
+)
+
+// errNotFound is returned when the API returns a 404.
+var errNotFound = errors.New("not found")
+
+// srcFiler implements filer.Filer using the Bitbucket source API to read
+// the files of a repository at a specific ref.
+type srcFiler struct {
+	Ctx    context.Context
+	Finder *Finder
+	Repo   string
+	Ref    string
+}
+
+func (f *srcFiler) ReadFile(name string) ([]byte, error) {
+	return f.Finder.get(f.Ctx, f.src(name))
+}
+
+func (f *srcFiler) ReadDir(dir string) ([]filer.File, error) {
+	var result []filer.File
+	next := strings.TrimSuffix(f.src(dir), "/") + "/"
+	for next != "" {
+		var page struct {
+			Next   string `json:"next"`
+			Values []struct {
+				Path string `json:"path"`
+				Type string `json:"type"`
+			} `json:"values"`
+		}
+		found, err := f.Finder.getJSON(f.Ctx, next, &page)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, errNotFound
+		}
+
+		for _, v := range page.Values {
+			result = append(result, filer.File{
+				Name:  path.Base(v.Path),
+				IsDir: v.Type == "commit_directory",
+			})
+		}
+
+		next = page.Next
+	}
+
+	return result, nil
+}
+
+func (f *srcFiler) Close() {}
+
+// src returns the source API URL for the given path. The ref is escaped
+// since refs such as the tags of nested modules can contain slashes.
+func (f *srcFiler) src(p string) string {
+	return f.Finder.url("/repositories/" + f.Repo + "/src/" + url.PathEscape(f.Ref) + "/" +
+		strings.TrimPrefix(p, "/"))
+}
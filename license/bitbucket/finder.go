@@ -0,0 +1,178 @@
+// Package bitbucket contains a license.Finder for repositories hosted on
+// Bitbucket Cloud.
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/license/detector"
+	"github.com/mitchellh/golicense/module"
+)
+
+const (
+	DefaultHost = "bitbucket.org"
+	DefaultURL  = "https://api.bitbucket.org/2.0"
+)
+
+// Finder implements license.Finder and detects the license of a module
+// from the license files in a Bitbucket repository using the Bitbucket
+// Cloud REST API[1].
+//
+// Bitbucket has no license API, so the license files are downloaded and
+// detected with go-license-detector. The files are read at the module
+// version if it exists in the repository, otherwise at the main branch.
+//
+// Only the Bitbucket Cloud API is supported. Bitbucket Server and Data
+// Center have a different API that this can't use.
+//
+// [1]: https://developer.atlassian.com/cloud/bitbucket/rest/
+type Finder struct {
+	// Host is the host name that import paths must start with to be looked
+	// up with this finder. This defaults to DefaultHost.
+	Host string
+
+	// URL is the base URL of the Bitbucket Cloud API, including the
+	// version. This defaults to DefaultURL.
+	URL string
+
+	// Token is an access token used to authenticate. This is optional for
+	// public repositories.
+	Token string
+
+	// Client is the HTTP client to use. If this is nil, http.DefaultClient
+	// is used.
+	Client *http.Client
+
+	// Threshold is the minimum similarity score for license text to match
+	// a license. See detector.Detector.
+	Threshold float32
+}
+
+// License implements license.Finder
+func (f *Finder) License(ctx context.Context, m module.Module) (*license.License, error) {
+	host := f.Host
+	if host == "" {
+		host = DefaultHost
+	}
+
+	matches := repoRe.FindStringSubmatch(strings.TrimPrefix(m.Path, host+"/"))
+	if !strings.HasPrefix(m.Path, host+"/") || matches == nil {
+		return nil, nil
+	}
+	repo := matches[1] + "/" + matches[2]
+
+	license.UpdateStatus(ctx, license.StatusNormal, "querying Bitbucket repository")
+	var r struct {
+		MainBranch struct {
+			Name string `json:"name"`
+		} `json:"mainbranch"`
+	}
+	found, err := f.getJSON(ctx, f.url("/repositories/"+repo), &r)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	// Try the exact version first, then fall back to the main branch.
+	// Tags of nested modules are prefixed with the module directory. Refs
+	// of pseudo-versions are commits, which are never prefixed.
+	refs := []string{r.MainBranch.Name}
+	if ref := m.Ref(); ref != "" {
+		dir := majorVersionRe.ReplaceAllString(strings.TrimPrefix(matches[3], "/"), "")
+		if dir != "" && ref == strings.TrimSuffix(m.Version, "+incompatible") {
+			ref = dir + "/" + ref
+		}
+
+		refs = append([]string{ref}, refs...)
+	}
+
+	for _, ref := range refs {
+		license.UpdateStatus(ctx, license.StatusNormal, fmt.Sprintf(
+			"detecting license at %s", ref))
+		d := detector.Detector{Threshold: f.Threshold}
+		lic, err := d.Detect(&srcFiler{Ctx: ctx, Finder: f, Repo: repo, Ref: ref})
+		if err == errNotFound {
+			// The ref doesn't exist, such as a version that was never tagged.
+			continue
+		}
+		if lic != nil {
+			lic.Source = "Bitbucket files at " + ref
+		}
+
+		return lic, err
+	}
+
+	return nil, nil
+}
+
+// url returns the full API URL for the given path.
+func (f *Finder) url(path string) string {
+	base := f.URL
+	if base == "" {
+		base = DefaultURL
+	}
+
+	return strings.TrimSuffix(base, "/") + path
+}
+
+// get performs an API request and returns the response body. If the API
+// returns a 404, errNotFound is returned.
+func (f *Finder) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if f.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+f.Token)
+	}
+
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return ioutil.ReadAll(resp.Body)
+
+	case http.StatusNotFound:
+		return nil, errNotFound
+
+	default:
+		return nil, fmt.Errorf("Bitbucket API request %s failed: %s", url, resp.Status)
+	}
+}
+
+// getJSON performs an API request and decodes the JSON response into
+// result. If the API returns a 404, false is returned with no error.
+func (f *Finder) getJSON(ctx context.Context, url string, result interface{}) (bool, error) {
+	data, err := f.get(ctx, url)
+	if err == errNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, json.Unmarshal(data, result)
+}
+
+// repoRe is the regexp matching the workspace and repository of an
+// import path with the host removed.
+var repoRe = regexp.MustCompile(`^([^/]+)/([^/]+)(/.*)?$`)
+
+// majorVersionRe matches a major version suffix such as "/v2" that isn't
+// part of the directory of a nested module.
+var majorVersionRe = regexp.MustCompile(`(^|/)v\d+$`)
@@ -0,0 +1,139 @@
+package bitbucket
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFinder(t *testing.T) {
+	mit, err := ioutil.ReadFile(filepath.Join("testdata", "mit.txt"))
+	require.NoError(t, err)
+
+	// Handlers run on the server's goroutines, so they only record what
+	// they're called with and the test checks it afterwards.
+	var refs, auths, paths []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/2.0/repositories/foo/bar", func(w http.ResponseWriter, r *http.Request) {
+		auths = append(auths, r.Header.Get("Authorization"))
+		w.Write([]byte(`{"mainbranch": {"name": "main"}}`))
+	})
+	mux.HandleFunc("/2.0/repositories/foo/bar/src/main/", func(w http.ResponseWriter, r *http.Request) {
+		refs = append(refs, "main")
+		switch r.URL.Path {
+		case "/2.0/repositories/foo/bar/src/main/":
+			w.Write([]byte(`{"values": [
+				{"path": "LICENSE", "type": "commit_file"},
+				{"path": "cmd", "type": "commit_directory"}
+			]}`))
+
+		case "/2.0/repositories/foo/bar/src/main/LICENSE":
+			w.Write(mit)
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	mux.HandleFunc("/2.0/repositories/foo/bar/src/cmd/v1.1.0/", func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath())
+		refs = append(refs, "cmd/v1.1.0")
+		switch r.URL.Path {
+		case "/2.0/repositories/foo/bar/src/cmd/v1.1.0/":
+			w.Write([]byte(`{"values": [{"path": "LICENSE", "type": "commit_file"}]}`))
+		case "/2.0/repositories/foo/bar/src/cmd/v1.1.0/LICENSE":
+			w.Write(mit)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	cases := []struct {
+		Name     string
+		Module   module.Module
+		Expected *license.License
+		Refs     []string
+	}{
+		{
+			"other host",
+			module.Module{Path: "github.com/foo/bar"},
+			nil,
+			nil,
+		},
+
+		{
+			"missing repository",
+			module.Module{Path: "bitbucket.org/foo/baz"},
+			nil,
+			nil,
+		},
+
+		{
+			"main branch",
+			module.Module{Path: "bitbucket.org/foo/bar"},
+			&license.License{
+				Name:       "MIT License",
+				SPDX:       "MIT",
+				Files:      []*license.File{{Path: "LICENSE", Name: "MIT License", SPDX: "MIT", Confidence: 0.9939759}},
+				Source:     "Bitbucket files at main",
+				Confidence: 0.9939759,
+			},
+			[]string{"main", "main"},
+		},
+
+		{
+			"version not tagged",
+			module.Module{Path: "bitbucket.org/foo/bar/cmd", Version: "v1.0.0"},
+			&license.License{
+				Name:       "MIT License",
+				SPDX:       "MIT",
+				Files:      []*license.File{{Path: "LICENSE", Name: "MIT License", SPDX: "MIT", Confidence: 0.9939759}},
+				Source:     "Bitbucket files at main",
+				Confidence: 0.9939759,
+			},
+			[]string{"main", "main"},
+		},
+
+		{
+			"nested module tag",
+			module.Module{Path: "bitbucket.org/foo/bar/cmd", Version: "v1.1.0"},
+			&license.License{
+				Name:       "MIT License",
+				SPDX:       "MIT",
+				Files:      []*license.File{{Path: "LICENSE", Name: "MIT License", SPDX: "MIT", Confidence: 0.9939759}},
+				Source:     "Bitbucket files at cmd/v1.1.0",
+				Confidence: 0.9939759,
+			},
+			[]string{"cmd/v1.1.0", "cmd/v1.1.0"},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			refs, auths, paths = nil, nil, nil
+			f := &Finder{URL: srv.URL + "/2.0", Token: "secret"}
+			actual, err := f.License(context.Background(), tt.Module)
+			require.NoError(t, err)
+			require.Equal(t, tt.Expected, actual)
+			require.Equal(t, tt.Refs, refs)
+			for _, v := range auths {
+				require.Equal(t, "Bearer secret", v)
+			}
+
+			// The tag of a nested module must be a single escaped segment
+			for _, v := range paths {
+				require.Contains(t, v, "/src/cmd%2Fv1.1.0/")
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+// Package cache contains a license.Finder that caches the results of
+// another finder on disk.
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+)
+
+// Finder implements license.Finder and caches the results of another
+// finder in a directory on disk. Results are keyed by the module path,
+// version and hash along with Key so that multiple finders can share a
+// directory. Including the hash means a result is never reused for a
+// module with a different hash, or for a module with a hash when it was
+// found for a module without one, such as an unverified module zip.
+//
+// Both found licenses and "not found" results are cached. Errors are never
+// cached. Modules without a version are never cached since the license
+// could be for any version.
+type Finder struct {
+	// Finder is the finder to cache the results of.
+	Finder license.Finder
+
+	// Key identifies the wrapped finder and any configuration that affects
+	// its results. Cached results are only used for the same key.
+	Key string
+
+	// Dir is the directory to store the cache in. This will be created
+	// if it doesn't exist.
+	Dir string
+
+	// TTL is how long a cached result is valid for. If this is zero,
+	// cached results never expire.
+	TTL time.Duration
+}
+
+// entry is the structure of a single cache file.
+type entry struct {
+	Key     string           `json:"key"`
+	Module  string           `json:"module"`
+	Created time.Time        `json:"created"`
+	License *license.License `json:"license"`
+}
+
+// License implements license.Finder
+func (f *Finder) License(ctx context.Context, m module.Module) (*license.License, error) {
+	if m.Version == "" {
+		return f.Finder.License(ctx, m)
+	}
+
+	id := m.Path + "@" + m.Version
+	if m.Hash != "" {
+		id += " " + m.Hash
+	}
+	path := filepath.Join(f.Dir, fmt.Sprintf(
+		"%x.json", sha256.Sum256([]byte(f.Key+"\x00"+id))))
+
+	// Read the cache. Any error reading is treated as a cache miss since
+	// we can always just look the license up again.
+	if data, err := ioutil.ReadFile(path); err == nil {
+		var e entry
+		if err := json.Unmarshal(data, &e); err == nil &&
+			e.Key == f.Key && e.Module == id &&
+			(f.TTL == 0 || time.Since(e.Created) < f.TTL) {
+			license.UpdateStatus(ctx, license.StatusNormal, "using cached license")
+			if e.License != nil {
+				e.License.Source += " (cached)"
+			}
+
+			return e.License, nil
+		}
+	}
+
+	lic, err := f.Finder.License(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(&entry{
+		Key:     f.Key,
+		Module:  id,
+		Created: time.Now(),
+		License: lic,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := write(path, data); err != nil {
+		license.UpdateStatus(ctx, license.StatusWarning, fmt.Sprintf(
+			"error writing cache: %s", err))
+	}
+
+	return lic, nil
+}
+
+// write writes the data to the path atomically so that concurrent readers
+// never see a partially written file.
+func write(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
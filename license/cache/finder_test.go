@@ -0,0 +1,130 @@
+package cache
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFinder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golicense")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	m := module.Module{Path: "github.com/foo/bar", Version: "v1.0.0"}
+	lic := &license.License{Name: "MIT License", SPDX: "MIT", Source: "test"}
+
+	var inner license.MockFinder
+	inner.On("License", mock.Anything, m).Return(lic, nil).Once()
+	f := &Finder{Finder: &inner, Key: "test", Dir: dir}
+
+	// The first lookup goes to the wrapped finder
+	actual, err := f.License(ctx, m)
+	require.NoError(t, err)
+	require.Equal(t, lic, actual)
+	inner.AssertExpectations(t)
+
+	// The second lookup is cached
+	actual, err = f.License(ctx, m)
+	require.NoError(t, err)
+	require.Equal(t, &license.License{
+		Name:   "MIT License",
+		SPDX:   "MIT",
+		Source: "test (cached)",
+	}, actual)
+	inner.AssertExpectations(t)
+
+	// A different key is not cached
+	var other license.MockFinder
+	other.On("License", mock.Anything, m).Return(nil, nil).Once()
+	f2 := &Finder{Finder: &other, Key: "other", Dir: dir}
+	actual, err = f2.License(ctx, m)
+	require.NoError(t, err)
+	require.Nil(t, actual)
+	other.AssertExpectations(t)
+
+	// Not found results are cached too
+	actual, err = f2.License(ctx, m)
+	require.NoError(t, err)
+	require.Nil(t, actual)
+	other.AssertExpectations(t)
+}
+
+func TestFinder_hash(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golicense")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	m := module.Module{Path: "github.com/foo/bar", Version: "v1.0.0"}
+	hashed := module.Module{Path: m.Path, Version: m.Version, Hash: "h1:abc"}
+	other := module.Module{Path: m.Path, Version: m.Version, Hash: "h1:def"}
+	lic := &license.License{Name: "MIT License", SPDX: "MIT", Source: "test"}
+
+	// Results are cached separately for each hash, including no hash
+	var inner license.MockFinder
+	inner.On("License", mock.Anything, m).Return(lic, nil).Once()
+	inner.On("License", mock.Anything, hashed).Return(lic, nil).Once()
+	inner.On("License", mock.Anything, other).Return(nil, nil).Once()
+	f := &Finder{Finder: &inner, Key: "test", Dir: dir}
+	for _, v := range []module.Module{m, hashed, other, hashed, other} {
+		_, err := f.License(ctx, v)
+		require.NoError(t, err)
+	}
+	inner.AssertExpectations(t)
+
+	actual, err := f.License(ctx, other)
+	require.NoError(t, err)
+	require.Nil(t, actual)
+}
+
+func TestFinder_ttl(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golicense")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	m := module.Module{Path: "github.com/foo/bar", Version: "v1.0.0"}
+	lic := &license.License{Name: "MIT License", SPDX: "MIT"}
+
+	var inner license.MockFinder
+	inner.On("License", mock.Anything, m).Return(lic, nil).Twice()
+	f := &Finder{Finder: &inner, Key: "test", Dir: dir, TTL: time.Nanosecond}
+
+	for i := 0; i < 2; i++ {
+		time.Sleep(time.Millisecond)
+		actual, err := f.License(ctx, m)
+		require.NoError(t, err)
+		require.Equal(t, lic, actual)
+	}
+
+	inner.AssertExpectations(t)
+}
+
+func TestFinder_error(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golicense")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	m := module.Module{Path: "github.com/foo/bar", Version: "v1.0.0"}
+
+	// Errors are never cached
+	var inner license.MockFinder
+	inner.On("License", mock.Anything, m).Return(nil, os.ErrNotExist).Twice()
+	f := &Finder{Finder: &inner, Key: "test", Dir: dir}
+	for i := 0; i < 2; i++ {
+		_, err := f.License(ctx, m)
+		require.Error(t, err)
+	}
+
+	inner.AssertExpectations(t)
+}
@@ -0,0 +1,320 @@
+// Package detector detects licenses from the contents of license files
+// using go-license-detector. This is used by finders that have access to
+// the license files of a module rather than an API that reports the
+// license directly.
+package detector
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/go-spdx"
+	"github.com/mitchellh/golicense/license"
+	"gopkg.in/src-d/go-license-detector.v2/licensedb"
+	"gopkg.in/src-d/go-license-detector.v2/licensedb/filer"
+)
+
+// DefaultThreshold is the default minimum score for license text to match
+// a license.
+const DefaultThreshold = 0.90
+
+const (
+	// ambiguousMargin is how close to the best score another license must
+	// score for the match to be ambiguous.
+	ambiguousMargin = 0.02
+
+	// maxCandidates is the maximum number of candidates to report.
+	maxCandidates = 3
+)
+
+// Detector detects the license of a set of files.
+//
+// The zero value is ready to use.
+type Detector struct {
+	// Offline, if true, will never make network requests. Licenses detected
+	// by go-license-detector only have an SPDX ID, so the full name is looked
+	// up using the SPDX API unless it is a well known license. If this is
+	// true and the license isn't well known, the SPDX ID is used as the name.
+	Offline bool
+
+	// Threshold is the minimum similarity score from 0 to 1 for license
+	// text to match a license. If this is zero, DefaultThreshold is used.
+	Threshold float32
+}
+
+// Detect detects the license of the files in the given filer. The root
+// of the filer should be the root of the project. If no license can be
+// detected then a nil license and nil error are returned.
+//
+// Every license file is detected separately and recorded in the Files of
+// the result. If the license files have different licenses, the result
+// is an SPDX expression requiring all of them, such as "MIT AND ISC",
+// since it isn't possible to tell whether the licenses are alternatives.
+//
+// If license text is found but no license scores above the threshold,
+// the result is inconclusive (see license.License.Inconclusive) with the
+// closest matches as candidates. If several licenses score closely, the
+// best match is used with a warning listing the candidates.
+func (d *Detector) Detect(fs filer.Filer) (*license.License, error) {
+	fs = &cachedFiler{Filer: fs}
+	paths, err := licenseFiles(fs)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*license.File
+	var warnings []string
+	var ambiguous, closest []*license.Candidate
+	for _, path := range paths {
+		m, err := d.detect(&singleFiler{Filer: fs, Path: path})
+		if err != nil {
+			return nil, err
+		}
+		if m.ID == "" {
+			if len(m.Candidates) > 0 {
+				warnings = append(warnings, d.inconclusiveWarning(path, m))
+				if closest == nil {
+					closest = m.Candidates
+				}
+			}
+
+			continue
+		}
+
+		name, err := d.name(m.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, &license.File{
+			Path:       path,
+			Name:       name,
+			SPDX:       m.ID,
+			Confidence: m.Score,
+		})
+		if len(m.Candidates) > 0 {
+			warnings = append(warnings, ambiguousWarning(path, m))
+			ambiguous = append(ambiguous, m.Candidates...)
+		}
+	}
+
+	// If no license files matched then fall back to detecting the license
+	// from the project as a whole, which includes the README.
+	if len(files) == 0 {
+		m, err := d.detect(fs)
+		if err != nil {
+			return nil, err
+		}
+
+		if m.ID == "" {
+			if len(m.Candidates) > 0 {
+				return &license.License{
+					Name:       "unknown license",
+					Warnings:   []string{d.inconclusiveWarning("", m)},
+					Candidates: m.Candidates,
+				}, nil
+			}
+
+			// The license files may still have had candidates even if the
+			// project as a whole didn't.
+			if closest != nil {
+				return &license.License{
+					Name:       "unknown license",
+					Warnings:   warnings,
+					Candidates: closest,
+				}, nil
+			}
+
+			return nil, nil
+		}
+
+		name, err := d.name(m.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		result := &license.License{
+			Name:       name,
+			SPDX:       m.ID,
+			Confidence: m.Score,
+			Candidates: m.Candidates,
+		}
+		if len(m.Candidates) > 0 {
+			result.Warnings = []string{ambiguousWarning("", m)}
+		}
+
+		return result, nil
+	}
+
+	var ids, names []string
+	seen := make(map[string]bool)
+	confidence := files[0].Confidence
+	for _, f := range files {
+		if f.Confidence < confidence {
+			confidence = f.Confidence
+		}
+
+		if !seen[f.SPDX] {
+			seen[f.SPDX] = true
+			ids = append(ids, f.SPDX)
+			names = append(names, f.Name)
+		}
+	}
+
+	result := &license.License{
+		Name:       strings.Join(names, " AND "),
+		SPDX:       strings.Join(ids, " AND "),
+		Files:      files,
+		Confidence: confidence,
+		Candidates: ambiguous,
+	}
+	if len(ids) > 1 {
+		result.Warnings = append(result.Warnings,
+			"multiple licenses detected, assuming all of them apply")
+	}
+	result.Warnings = append(result.Warnings, warnings...)
+
+	return result, nil
+}
+
+// match is the result of detecting the license of license text.
+type match struct {
+	ID    string  // ID is the SPDX ID of the best match, if any
+	Score float32 // Score is the score of the best match
+
+	// Candidates are the closest matches, highest score first, if no
+	// license scored above the threshold or if several licenses scored
+	// closely. Otherwise this is empty.
+	Candidates []*license.Candidate
+}
+
+// detect returns the highest matching license in the filer. If there is
+// no match, the ID of the result is empty.
+func (d *Detector) detect(fs filer.Filer) (match, error) {
+	ms, err := licensedb.Detect(fs)
+	if err == licensedb.ErrNoLicenseFound {
+		return match{}, nil
+	}
+	if err != nil {
+		return match{}, err
+	}
+
+	return d.best(ms), nil
+}
+
+// best returns the highest scoring license of the licenses and scores
+// reported by licensedb.
+func (d *Detector) best(ms map[string]float32) match {
+	// Sort the licenses by score. Some licenses have identical text (such
+	// as "MPL-2.0" and "MPL-2.0-no-copyleft-exception") so ties are broken
+	// by choosing the shortest, then lowest, ID to keep results stable.
+	cs := make([]*license.Candidate, 0, len(ms))
+	for id, v := range ms {
+		cs = append(cs, &license.Candidate{SPDX: id, Confidence: v})
+	}
+	sort.Slice(cs, func(i, j int) bool {
+		a, b := cs[i], cs[j]
+		if a.Confidence != b.Confidence {
+			return a.Confidence > b.Confidence
+		}
+		if len(a.SPDX) != len(b.SPDX) {
+			return len(a.SPDX) < len(b.SPDX)
+		}
+
+		return a.SPDX < b.SPDX
+	})
+	if len(cs) == 0 {
+		return match{}
+	}
+	if len(cs) > maxCandidates {
+		cs = cs[:maxCandidates]
+	}
+
+	best := cs[0]
+	if best.Confidence < d.threshold() {
+		return match{Candidates: cs}
+	}
+
+	// The match is ambiguous if another license scores closely. Licenses
+	// with the same score have identical text so they aren't ambiguous.
+	result := match{ID: best.SPDX, Score: best.Confidence}
+	ambiguous := false
+	for i, c := range cs {
+		if best.Confidence-c.Confidence > ambiguousMargin {
+			cs = cs[:i]
+			break
+		}
+
+		ambiguous = ambiguous || c.Confidence < best.Confidence
+	}
+	if ambiguous {
+		result.Candidates = cs
+	}
+
+	return result
+}
+
+// threshold returns the minimum score for a match.
+func (d *Detector) threshold() float32 {
+	if d.Threshold > 0 {
+		return d.Threshold
+	}
+
+	return DefaultThreshold
+}
+
+// inconclusiveWarning returns the warning for license text at path that
+// didn't match any license. An empty path is the project as a whole.
+func (d *Detector) inconclusiveWarning(path string, m match) string {
+	prefix := "no license matched"
+	if path != "" {
+		prefix += " in " + path
+	}
+
+	threshold := strings.TrimSuffix(fmt.Sprintf("%.1f", d.threshold()*100), ".0")
+	return fmt.Sprintf("%s above %s%%, closest: %s",
+		prefix, threshold, candidateList(m.Candidates))
+}
+
+// ambiguousWarning returns the warning for license text at path that
+// closely matched several licenses. An empty path is the project as a
+// whole.
+func ambiguousWarning(path string, m match) string {
+	prefix := "ambiguous license match"
+	if path != "" {
+		prefix += " in " + path
+	}
+
+	return fmt.Sprintf("%s between %s, using %s", prefix, candidateList(m.Candidates), m.ID)
+}
+
+// candidateList returns a human-friendly list of candidates such as
+// "MIT (91.2%), MIT-0 (90.5%)".
+func candidateList(cs []*license.Candidate) string {
+	parts := make([]string, len(cs))
+	for i, c := range cs {
+		parts[i] = fmt.Sprintf("%s (%.1f%%)", c.SPDX, c.Confidence*100)
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// name returns the full name of the license with the given SPDX ID.
+func (d *Detector) name(id string) (string, error) {
+	if v, ok := names[id]; ok {
+		return v, nil
+	}
+
+	if d.Offline {
+		return id, nil
+	}
+
+	// License detection only returns SPDX IDs but we want the complete name.
+	lic, err := spdx.License(id)
+	if err != nil {
+		return "", fmt.Errorf("error looking up license %q: %s", id, err)
+	}
+
+	return lic.Name, nil
+}
@@ -0,0 +1,137 @@
+package detector
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/src-d/go-license-detector.v2/licensedb/filer"
+)
+
+func TestDetector(t *testing.T) {
+	cases := []struct {
+		Dir       string
+		Threshold float32
+		Expected  *license.License
+	}{
+		{
+			"single",
+			0,
+			&license.License{
+				Name:       "MIT License",
+				SPDX:       "MIT",
+				Files:      []*license.File{{Path: "LICENSE", Name: "MIT License", SPDX: "MIT", Confidence: 0.9939759}},
+				Confidence: 0.9939759,
+			},
+		},
+
+		{
+			"multi",
+			0,
+			&license.License{
+				Name:     "MIT License AND Mozilla Public License 2.0",
+				SPDX:     "MIT AND MPL-2.0",
+				Warnings: []string{"multiple licenses detected, assuming all of them apply"},
+				Files: []*license.File{
+					{Path: "LICENSE-MIT", Name: "MIT License", SPDX: "MIT", Confidence: 0.9939759},
+					{Path: "LICENSES/MPL-2.0.txt", Name: "Mozilla Public License 2.0", SPDX: "MPL-2.0", Confidence: 0.9779316},
+				},
+				Confidence: 0.9779316,
+			},
+		},
+
+		{
+			"single",
+			0.995,
+			&license.License{
+				Name: "unknown license",
+				Warnings: []string{
+					"no license matched above 99.5%, closest: MIT (99.4%), MIT-feh (84.1%)",
+				},
+				Candidates: []*license.Candidate{
+					{SPDX: "MIT", Confidence: 0.9939759},
+					{SPDX: "MIT-feh", Confidence: 0.84146345},
+				},
+			},
+		},
+
+		{
+			"none",
+			0,
+			nil,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(fmt.Sprintf("%s-%v", tt.Dir, tt.Threshold), func(t *testing.T) {
+			fs, err := filer.FromDirectory(filepath.Join("testdata", tt.Dir))
+			require.NoError(t, err)
+			defer fs.Close()
+
+			d := &Detector{Offline: true, Threshold: tt.Threshold}
+			actual, err := d.Detect(fs)
+			require.NoError(t, err)
+			require.Equal(t, tt.Expected, actual)
+		})
+	}
+}
+
+func TestDetector_best(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Scores   map[string]float32
+		Expected match
+	}{
+		{
+			"no matches",
+			nil,
+			match{},
+		},
+
+		{
+			"single match",
+			map[string]float32{"MIT": 0.99, "MIT-feh": 0.84},
+			match{ID: "MIT", Score: 0.99},
+		},
+
+		{
+			"identical text",
+			map[string]float32{"MPL-2.0-no-copyleft-exception": 0.97, "MPL-2.0": 0.97},
+			match{ID: "MPL-2.0", Score: 0.97},
+		},
+
+		{
+			"ambiguous",
+			map[string]float32{"BSD-3-Clause": 0.95, "BSD-2-Clause": 0.94, "MIT": 0.80},
+			match{
+				ID:    "BSD-3-Clause",
+				Score: 0.95,
+				Candidates: []*license.Candidate{
+					{SPDX: "BSD-3-Clause", Confidence: 0.95},
+					{SPDX: "BSD-2-Clause", Confidence: 0.94},
+				},
+			},
+		},
+
+		{
+			"below threshold",
+			map[string]float32{"BSD-3-Clause": 0.88, "BSD-2-Clause": 0.85, "MIT": 0.80, "ISC": 0.79},
+			match{
+				Candidates: []*license.Candidate{
+					{SPDX: "BSD-3-Clause", Confidence: 0.88},
+					{SPDX: "BSD-2-Clause", Confidence: 0.85},
+					{SPDX: "MIT", Confidence: 0.80},
+				},
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			var d Detector
+			require.Equal(t, tt.Expected, d.best(tt.Scores))
+		})
+	}
+}
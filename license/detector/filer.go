@@ -0,0 +1,145 @@
+package detector
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+
+	"gopkg.in/src-d/go-license-detector.v2/licensedb/filer"
+)
+
+// licenseFiles returns the paths of the files in the filer that may
+// contain a license: files in the root with a license-like name, using
+// the same rules as go-license-detector, and every file in a license
+// directory such as "LICENSES". An error reading the root is returned
+// as-is.
+func licenseFiles(fs filer.Filer) ([]string, error) {
+	files, err := fs.ReadDir("")
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for _, f := range files {
+		name := strings.ToLower(f.Name)
+		if !f.IsDir {
+			if licenseFileRe.MatchString(name) {
+				result = append(result, f.Name)
+			}
+
+			continue
+		}
+
+		if !licenseDirRe.MatchString(name) {
+			continue
+		}
+
+		subfiles, err := fs.ReadDir(f.Name)
+		if err != nil {
+			continue
+		}
+		for _, sf := range subfiles {
+			if !sf.IsDir {
+				result = append(result, path.Join(f.Name, sf.Name))
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// cachedFiler is a filer.Filer that caches the results of reads so that
+// detecting each license file separately doesn't read anything twice.
+// This matters for filers backed by network APIs.
+type cachedFiler struct {
+	filer.Filer
+
+	lock  sync.Mutex
+	files map[string][]byte
+	dirs  map[string][]filer.File
+}
+
+func (f *cachedFiler) ReadFile(name string) ([]byte, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if v, ok := f.files[name]; ok {
+		return v, nil
+	}
+
+	v, err := f.Filer.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.files == nil {
+		f.files = make(map[string][]byte)
+	}
+	f.files[name] = v
+	return v, nil
+}
+
+func (f *cachedFiler) ReadDir(name string) ([]filer.File, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if v, ok := f.dirs[name]; ok {
+		return v, nil
+	}
+
+	v, err := f.Filer.ReadDir(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.dirs == nil {
+		f.dirs = make(map[string][]filer.File)
+	}
+	f.dirs[name] = v
+	return v, nil
+}
+
+// singleFiler is a filer.Filer that only lists the single file at Path,
+// so that the license of one file can be detected. The file is listed in
+// the root as "LICENSE" so that go-license-detector considers it a license
+// file regardless of its name. Other files can still be read since license
+// files may refer to another file.
+type singleFiler struct {
+	filer.Filer
+	Path string
+}
+
+func (f *singleFiler) ReadFile(name string) ([]byte, error) {
+	if name == f.name() {
+		name = f.Path
+	}
+
+	return f.Filer.ReadFile(name)
+}
+
+func (f *singleFiler) ReadDir(name string) ([]filer.File, error) {
+	if name != "" {
+		return nil, fmt.Errorf("not found: %s", name)
+	}
+
+	return []filer.File{{Name: f.name()}}, nil
+}
+
+// name is the name of the file in the root. The extension is kept since
+// it determines how the file is preprocessed, such as for Markdown.
+func (f *singleFiler) name() string {
+	return "LICENSE" + path.Ext(f.Path)
+}
+
+var (
+	// licenseNames are the base names of license files, copied from
+	// go-license-detector which doesn't export them.
+	licenseNames = `li[cs]en[cs]e(s?)|legal|copy(left|right|ing)|unlicense|` +
+		`l?gpl([-_ v]?)(\d\.?\d)?|bsd|mit|apache`
+
+	licenseFileRe = regexp.MustCompile(
+		`^(|.*[-_. ])(` + licenseNames + `)(|[-_. ].*)$`)
+	licenseDirRe = regexp.MustCompile(`^(` + licenseNames + `)$`)
+)
@@ -0,0 +1,51 @@
+package detector
+
+import "strings"
+
+// names is a mapping of well known SPDX IDs to their full names. This lets
+// us avoid a network request for the most common licenses.
+var names = map[string]string{
+	"0BSD":              "BSD Zero Clause License",
+	"AGPL-3.0":          "GNU Affero General Public License v3.0",
+	"AGPL-3.0-only":     "GNU Affero General Public License v3.0 only",
+	"AGPL-3.0-or-later": "GNU Affero General Public License v3.0 or later",
+	"Apache-2.0":        "Apache License 2.0",
+	"BSD-2-Clause":      "BSD 2-Clause \"Simplified\" License",
+	"BSD-3-Clause":      "BSD 3-Clause \"New\" or \"Revised\" License",
+	"BSL-1.0":           "Boost Software License 1.0",
+	"CC0-1.0":           "Creative Commons Zero v1.0 Universal",
+	"EPL-1.0":           "Eclipse Public License 1.0",
+	"EPL-2.0":           "Eclipse Public License 2.0",
+	"GPL-2.0":           "GNU General Public License v2.0 only",
+	"GPL-2.0-only":      "GNU General Public License v2.0 only",
+	"GPL-2.0-or-later":  "GNU General Public License v2.0 or later",
+	"GPL-3.0":           "GNU General Public License v3.0 only",
+	"GPL-3.0-only":      "GNU General Public License v3.0 only",
+	"GPL-3.0-or-later":  "GNU General Public License v3.0 or later",
+	"ISC":               "ISC License",
+	"LGPL-2.1":          "GNU Lesser General Public License v2.1 only",
+	"LGPL-2.1-only":     "GNU Lesser General Public License v2.1 only",
+	"LGPL-2.1-or-later": "GNU Lesser General Public License v2.1 or later",
+	"LGPL-3.0":          "GNU Lesser General Public License v3.0 only",
+	"LGPL-3.0-only":     "GNU Lesser General Public License v3.0 only",
+	"LGPL-3.0-or-later": "GNU Lesser General Public License v3.0 or later",
+	"MIT":               "MIT License",
+	"MPL-2.0":           "Mozilla Public License 2.0",
+	"Unlicense":         "The Unlicense",
+	"WTFPL":             "Do What The F*ck You Want To Public License",
+	"Zlib":              "zlib License",
+}
+
+// LookupID returns the SPDX ID for a well known license given an ID with
+// any casing, such as "mit" or "apache-2.0". This is useful for APIs that
+// return lowercase license keys. If the license isn't well known, false
+// is returned.
+func LookupID(v string) (string, bool) {
+	for id := range names {
+		if strings.EqualFold(id, v) {
+			return id, true
+		}
+	}
+
+	return "", false
+}
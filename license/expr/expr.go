@@ -0,0 +1,261 @@
+// Package expr parses SPDX license expressions such as
+// "MIT OR Apache-2.0" and "GPL-2.0-or-later WITH Classpath-exception-2.0".
+//
+// The grammar is defined in Annex D of the SPDX specification. Operators
+// are matched case-insensitively and WITH binds tighter than AND, which
+// binds tighter than OR.
+package expr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Expr is a parsed license expression. This is one of *License, *And or
+// *Or.
+type Expr interface {
+	// String returns the expression in canonical form, with parentheses
+	// only where required.
+	String() string
+
+	expr()
+}
+
+// License is a single license in an expression.
+type License struct {
+	ID        string // ID is the license ID, such as "MIT"
+	OrLater   bool   // OrLater is true for the "+" operator, such as "GPL-2.0+"
+	Exception string // Exception is the exception ID after WITH, if any
+}
+
+// And is a conjunctive expression: both licenses apply.
+type And struct {
+	Left, Right Expr
+}
+
+// Or is a disjunctive expression: either license may be chosen.
+type Or struct {
+	Left, Right Expr
+}
+
+func (*License) expr() {}
+func (*And) expr()     {}
+func (*Or) expr()      {}
+
+func (l *License) String() string {
+	result := l.ID
+	if l.OrLater {
+		result += "+"
+	}
+	if l.Exception != "" {
+		result += " WITH " + l.Exception
+	}
+
+	return result
+}
+
+func (e *And) String() string {
+	return group(e.Left, false) + " AND " + group(e.Right, false)
+}
+
+func (e *Or) String() string {
+	return group(e.Left, true) + " OR " + group(e.Right, true)
+}
+
+// group returns the string form of e, wrapped in parentheses if it is an
+// OR within an AND.
+func group(e Expr, inOr bool) string {
+	if _, ok := e.(*Or); ok && !inOr {
+		return "(" + e.String() + ")"
+	}
+
+	return e.String()
+}
+
+// Licenses returns all the licenses in the expression, in order.
+func Licenses(e Expr) []*License {
+	switch e := e.(type) {
+	case *License:
+		return []*License{e}
+
+	case *And:
+		return append(Licenses(e.Left), Licenses(e.Right)...)
+
+	case *Or:
+		return append(Licenses(e.Left), Licenses(e.Right)...)
+
+	default:
+		return nil
+	}
+}
+
+// Parse parses a license expression.
+func Parse(s string) (Expr, error) {
+	p := &parser{tokens: tokenize(s)}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("empty license expression")
+	}
+
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid license expression %q: %s", s, err)
+	}
+	if tok := p.peek(); tok != "" {
+		return nil, fmt.Errorf("invalid license expression %q: unexpected %q", s, tok)
+	}
+
+	return e, nil
+}
+
+// IsCompound returns true if s is a valid expression with more than one
+// license, such as "MIT OR Apache-2.0".
+func IsCompound(s string) bool {
+	e, err := Parse(s)
+	if err != nil {
+		return false
+	}
+
+	_, ok := e.(*License)
+	return !ok
+}
+
+// tokenize splits the expression into tokens. Parentheses are always
+// separate tokens.
+func tokenize(s string) []string {
+	s = strings.Replace(s, "(", " ( ", -1)
+	s = strings.Replace(s, ")", " ) ", -1)
+	return strings.Fields(s)
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() string {
+	tok := p.peek()
+	if tok != "" {
+		p.pos++
+	}
+
+	return tok
+}
+
+// isOp returns true if the next token is the given operator.
+func (p *parser) isOp(op string) bool {
+	return strings.ToUpper(p.peek()) == op
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isOp("OR") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &Or{Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseWith()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isOp("AND") {
+		p.next()
+		right, err := p.parseWith()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &And{Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseWith() (Expr, error) {
+	e, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.isOp("WITH") {
+		return e, nil
+	}
+	p.next()
+
+	l, ok := e.(*License)
+	if !ok {
+		return nil, fmt.Errorf("WITH must follow a license")
+	}
+
+	exception := p.next()
+	if !isID(exception) {
+		return nil, fmt.Errorf("expected exception after WITH")
+	}
+
+	l.Exception = exception
+	return l, nil
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	tok := p.next()
+	switch {
+	case tok == "":
+		return nil, fmt.Errorf("unexpected end of expression")
+
+	case tok == "(":
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+
+		return e, nil
+
+	case !isID(tok):
+		return nil, fmt.Errorf("unexpected %q", tok)
+	}
+
+	l := &License{ID: tok}
+	if strings.HasSuffix(l.ID, "+") {
+		l.ID = strings.TrimSuffix(l.ID, "+")
+		l.OrLater = true
+	}
+	if l.ID == "" {
+		return nil, fmt.Errorf("unexpected %q", tok)
+	}
+
+	return l, nil
+}
+
+// isID returns true if tok can be a license or exception ID.
+func isID(tok string) bool {
+	switch strings.ToUpper(tok) {
+	case "", "(", ")", "AND", "OR", "WITH":
+		return false
+	}
+
+	return true
+}
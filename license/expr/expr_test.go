@@ -0,0 +1,102 @@
+package expr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		Input    string
+		Expected Expr
+		String   string
+		Err      bool
+	}{
+		{
+			"MIT",
+			&License{ID: "MIT"},
+			"MIT",
+			false,
+		},
+
+		{
+			"GPL-2.0+",
+			&License{ID: "GPL-2.0", OrLater: true},
+			"GPL-2.0+",
+			false,
+		},
+
+		{
+			"MIT OR Apache-2.0",
+			&Or{&License{ID: "MIT"}, &License{ID: "Apache-2.0"}},
+			"MIT OR Apache-2.0",
+			false,
+		},
+
+		{
+			"mit or apache-2.0",
+			&Or{&License{ID: "mit"}, &License{ID: "apache-2.0"}},
+			"mit OR apache-2.0",
+			false,
+		},
+
+		{
+			"MIT AND BSD-3-Clause OR Apache-2.0",
+			&Or{
+				&And{&License{ID: "MIT"}, &License{ID: "BSD-3-Clause"}},
+				&License{ID: "Apache-2.0"},
+			},
+			"MIT AND BSD-3-Clause OR Apache-2.0",
+			false,
+		},
+
+		{
+			"MIT AND (BSD-3-Clause OR Apache-2.0)",
+			&And{
+				&License{ID: "MIT"},
+				&Or{&License{ID: "BSD-3-Clause"}, &License{ID: "Apache-2.0"}},
+			},
+			"MIT AND (BSD-3-Clause OR Apache-2.0)",
+			false,
+		},
+
+		{
+			"GPL-2.0-or-later WITH Classpath-exception-2.0 OR MIT",
+			&Or{
+				&License{ID: "GPL-2.0-or-later", Exception: "Classpath-exception-2.0"},
+				&License{ID: "MIT"},
+			},
+			"GPL-2.0-or-later WITH Classpath-exception-2.0 OR MIT",
+			false,
+		},
+
+		{"", nil, "", true},
+		{"MIT OR", nil, "", true},
+		{"(MIT", nil, "", true},
+		{"MIT License", nil, "", true},
+		{"MIT WITH", nil, "", true},
+		{"(MIT OR BSD-2-Clause) WITH foo", nil, "", true},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Input, func(t *testing.T) {
+			actual, err := Parse(tt.Input)
+			if tt.Err {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.Expected, actual)
+			require.Equal(t, tt.String, actual.String())
+		})
+	}
+}
+
+func TestIsCompound(t *testing.T) {
+	require.False(t, IsCompound("MIT"))
+	require.False(t, IsCompound("MIT License"))
+	require.True(t, IsCompound("MIT OR Apache-2.0"))
+	require.True(t, IsCompound("(MIT AND BSD-3-Clause)"))
+}
@@ -25,14 +25,27 @@ type Translator interface {
 // Translate translates the given module or returns the same module if
 // no translation is necessary.
 func Translate(ctx context.Context, m module.Module, ts []Translator) module.Module {
+	m, _ = TranslateChain(ctx, m, ts)
+	return m
+}
+
+// TranslateChain translates the given module like Translate and also
+// returns the path of the module after each translation that changed the
+// path, in order.
+func TranslateChain(ctx context.Context, m module.Module, ts []Translator) (module.Module, []string) {
+	var chain []string
 	for _, t := range ts {
 		n, ok := t.Translate(ctx, m)
 		if ok {
+			if n.Path != m.Path {
+				chain = append(chain, n.Path)
+			}
+
 			m = n
 		}
 	}
 
-	return m
+	return m, chain
 }
 
 // Find finds the license for the given module using a set of finders.
@@ -42,6 +55,9 @@ func Translate(ctx context.Context, m module.Module, ts []Translator) module.Mod
 // an error, other finders are still attempted. It is possible for a non-nil
 // license to be returned WITH a non-nil error meaning a different lookup
 // failed.
+//
+// An inconclusive license (see License.Inconclusive) is only returned if
+// no later finder returns a license.
 func Find(ctx context.Context, m module.Module, fs []Finder) (r *License, rerr error) {
 	for _, f := range fs {
 		lic, err := f.License(ctx, m)
@@ -49,6 +65,13 @@ func Find(ctx context.Context, m module.Module, fs []Finder) (r *License, rerr e
 			rerr = multierror.Append(rerr, err)
 			continue
 		}
+		if lic.Inconclusive() {
+			if r == nil {
+				r = lic
+			}
+
+			continue
+		}
 		if lic != nil {
 			r = lic
 			break
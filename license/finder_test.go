@@ -0,0 +1,65 @@
+package license
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+)
+
+// prefixTranslator replaces a path prefix.
+type prefixTranslator struct {
+	From, To string
+}
+
+func (t *prefixTranslator) Translate(ctx context.Context, m module.Module) (module.Module, bool) {
+	if !strings.HasPrefix(m.Path, t.From) {
+		return m, false
+	}
+
+	m.Path = t.To + strings.TrimPrefix(m.Path, t.From)
+	return m, true
+}
+
+func TestTranslateChain(t *testing.T) {
+	ts := []Translator{
+		&prefixTranslator{From: "gopkg.in/", To: "github.com/go-"},
+		&prefixTranslator{From: "example.com/", To: "example.com/"},
+		&prefixTranslator{From: "github.com/go-yaml", To: "github.com/yaml"},
+	}
+
+	m, chain := TranslateChain(context.Background(), module.Module{Path: "gopkg.in/yaml"}, ts)
+	require.Equal(t, "github.com/yaml", m.Path)
+	require.Equal(t, []string{"github.com/go-yaml", "github.com/yaml"}, chain)
+
+	m, chain = TranslateChain(context.Background(), module.Module{Path: "example.com/foo"}, ts)
+	require.Equal(t, "example.com/foo", m.Path)
+	require.Empty(t, chain)
+}
+
+func TestFind_inconclusive(t *testing.T) {
+	inconclusive := &License{
+		Name:       "unknown license",
+		Candidates: []*Candidate{{SPDX: "BSD-3-Clause", Confidence: 0.88}},
+	}
+	mit := &License{Name: "MIT License", SPDX: "MIT"}
+
+	f1 := new(MockFinder)
+	f1.On("License", context.Background(), module.Module{Path: "foo"}).Return(inconclusive, nil)
+	f2 := new(MockFinder)
+	f2.On("License", context.Background(), module.Module{Path: "foo"}).Return(mit, nil)
+	f3 := new(MockFinder)
+	f3.On("License", context.Background(), module.Module{Path: "foo"}).Return(nil, nil)
+
+	// A later conclusive license takes priority
+	lic, err := Find(context.Background(), module.Module{Path: "foo"}, []Finder{f1, f2})
+	require.NoError(t, err)
+	require.Equal(t, mit, lic)
+
+	// Otherwise the inconclusive license is used
+	lic, err = Find(context.Background(), module.Module{Path: "foo"}, []Finder{f1, f3})
+	require.NoError(t, err)
+	require.Equal(t, inconclusive, lic)
+}
@@ -0,0 +1,77 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/google/go-github/v18/github"
+	"gopkg.in/src-d/go-license-detector.v2/licensedb/filer"
+)
+
+// contentsFiler implements filer.Filer using the GitHub Repository Contents
+// API to read files at a specific ref. Files are only requested when read,
+// so license detection only downloads the files that look like licenses.
+//
+// If Dir is set, all names are relative to that directory of the repository.
+// If Ref is empty, the default branch is used.
+type contentsFiler struct {
+	Ctx    context.Context
+	Client *github.Client
+	Owner  string
+	Repo   string
+	Ref    string
+	Dir    string
+}
+
+func (f *contentsFiler) ReadFile(name string) ([]byte, error) {
+	var fc *github.RepositoryContent
+	err := retry(f.Ctx, func() error {
+		var err error
+		fc, _, _, err = f.Client.Repositories.GetContents(
+			f.Ctx, f.Owner, f.Repo, path.Join(f.Dir, name), f.opts())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if fc == nil {
+		return nil, fmt.Errorf("not a file: %s", name)
+	}
+
+	content, err := fc.GetContent()
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(content), nil
+}
+
+func (f *contentsFiler) ReadDir(dir string) ([]filer.File, error) {
+	var dc []*github.RepositoryContent
+	err := retry(f.Ctx, func() error {
+		var err error
+		_, dc, _, err = f.Client.Repositories.GetContents(
+			f.Ctx, f.Owner, f.Repo, path.Join(f.Dir, dir), f.opts())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]filer.File, 0, len(dc))
+	for _, c := range dc {
+		result = append(result, filer.File{
+			Name:  path.Base(c.GetPath()),
+			IsDir: c.GetType() == "dir",
+		})
+	}
+
+	return result, nil
+}
+
+func (f *contentsFiler) Close() {}
+
+func (f *contentsFiler) opts() *github.RepositoryContentGetOptions {
+	return &github.RepositoryContentGetOptions{Ref: f.Ref}
+}
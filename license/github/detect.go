@@ -5,43 +5,21 @@ import (
 	"fmt"
 
 	"github.com/google/go-github/v18/github"
-	"github.com/mitchellh/go-spdx"
 	"github.com/mitchellh/golicense/license"
-	"gopkg.in/src-d/go-license-detector.v2/licensedb"
+	"github.com/mitchellh/golicense/license/detector"
 	"gopkg.in/src-d/go-license-detector.v2/licensedb/filer"
 )
 
-// detect uses go-license-detector as a fallback.
-func detect(rl *github.RepositoryLicense) (*license.License, error) {
-	ms, err := licensedb.Detect(&filerImpl{License: rl})
-	if err != nil {
-		return nil, err
+// detect uses go-license-detector as a fallback. threshold is the minimum
+// score for a match, see detector.Detector.
+func detect(rl *github.RepositoryLicense, threshold float32) (*license.License, error) {
+	d := detector.Detector{Threshold: threshold}
+	lic, err := d.Detect(&filerImpl{License: rl})
+	if lic != nil {
+		lic.Source = "GitHub API with licensedb"
 	}
 
-	// Find the highest matching license
-	var highest float32
-	current := ""
-	for id, v := range ms {
-		if v > 0.90 && v > highest {
-			highest = v
-			current = id
-		}
-	}
-
-	if current == "" {
-		return nil, nil
-	}
-
-	// License detection only returns SPDX IDs but we want the complete name.
-	lic, err := spdx.License(current)
-	if err != nil {
-		return nil, fmt.Errorf("error looking up license %q: %s", current, err)
-	}
-
-	return &license.License{
-		Name: lic.Name,
-		SPDX: lic.ID,
-	}, nil
+	return lic, err
 }
 
 // filerImpl implements filer.Filer to return the license text directly
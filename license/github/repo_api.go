@@ -3,14 +3,20 @@ package github
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/google/go-github/v18/github"
 	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/license/detector"
 	"github.com/mitchellh/golicense/module"
 )
 
+// DefaultHost is the host name of import paths on the public GitHub.
+const DefaultHost = "github.com"
+
 // RepoAPI implements license.Finder and looks up the license of a module
 // using the GitHub Repository License API[1].
 //
@@ -18,55 +24,273 @@ import (
 // Therefore it is theoretically possible for a dependency to have a different
 // license based on the exact match of the SHA (the project changed licenses).
 // In practice, this is quite rare so it is up to the caller to determine if
-// this is an acceptable risk or not.
+// this is an acceptable risk or not. Set ExactVersion to detect the license
+// at the exact version instead.
 //
 // [1]: https://developer.github.com/v3/licenses/#get-the-contents-of-a-repositorys-license
 type RepoAPI struct {
 	Client *github.Client
+
+	// Host is the host name that import paths must start with to be looked
+	// up with this finder. This defaults to DefaultHost. For GitHub
+	// Enterprise, set this to the host of the instance and create Client
+	// with github.NewEnterpriseClient.
+	Host string
+
+	// ExactVersion, if true, detects the license from the license files in
+	// the repository at the tag or commit of the module version rather than
+	// the default branch. If the license differs from the license of the
+	// default branch, a warning is added to the license.
+	//
+	// This requires additional API requests for every module.
+	ExactVersion bool
+
+	// Threshold is the minimum similarity score for license text to match
+	// a license. See detector.Detector.
+	Threshold float32
 }
 
 // License implements license.Finder
 func (f *RepoAPI) License(ctx context.Context, m module.Module) (*license.License, error) {
-	matches := githubRe.FindStringSubmatch(m.Path)
-	if matches == nil {
+	owner, repo, dir, ok := f.repo(m.Path)
+	if !ok {
 		return nil, nil
 	}
+	dir = majorVersionRe.ReplaceAllString(dir, "")
+
+	// Nested modules such as "github.com/hashicorp/consul/api" may have
+	// their own license in their subdirectory, which takes priority over
+	// the license of the repository.
+	var dirs []string
+	if dir != "" {
+		for _, p := range m.ModulePaths() {
+			if _, _, d, ok := f.repo(p); ok {
+				dirs = append(dirs, d)
+			}
+		}
+	}
+
+	head, err := f.headLicense(ctx, owner, repo, dirs)
+	if !f.ExactVersion {
+		return head, err
+	}
+	if err != nil {
+		// The License API returns a 404 if no license is detected on the
+		// default branch, but there may still be one at the exact version.
+		if !isNotFound(err) {
+			return nil, err
+		}
+
+		head = nil
+	}
+
+	ref := m.Ref()
+	if ref == "" {
+		return head, nil
+	}
+
+	// Tags of nested modules are prefixed with the module directory. Refs
+	// of pseudo-versions are commits, which are never prefixed.
+	if dir != "" && ref == strings.TrimSuffix(m.Version, "+incompatible") {
+		ref = dir + "/" + ref
+	}
+
+	license.UpdateStatus(ctx, license.StatusNormal, fmt.Sprintf(
+		"detecting license at %s", ref))
+	lic, err := f.detect(ctx, owner, repo, ref, append(dirs, ""))
+	if isNotFound(err) {
+		// The ref doesn't exist, such as a version that was never tagged.
+		return withWarning(head, fmt.Sprintf(
+			"%s not found in repository, using default branch license", ref)), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if lic == nil {
+		if head == nil {
+			return nil, nil
+		}
+
+		return withWarning(head, fmt.Sprintf(
+			"no license detected at %s, using default branch license", ref)), nil
+	}
+
+	if lic.Inconclusive() && head != nil {
+		return withWarning(head, fmt.Sprintf(
+			"license at %s is inconclusive, using default branch license", ref)), nil
+	}
+
+	if head != nil && !sameLicense(lic, head) {
+		lic.Warnings = append(lic.Warnings, fmt.Sprintf(
+			"license at %s differs from default branch license %q",
+			ref, licenseID(head)))
+	}
+
+	return lic, nil
+}
+
+// repo returns the owner, repository name and the module directory within
+// the repository for an import path, or false if the import path is not
+// a repository on the host. The directory is empty for modules at the
+// root of the repository.
+func (f *RepoAPI) repo(path string) (string, string, string, bool) {
+	host := f.Host
+	if host == "" {
+		host = DefaultHost
+	}
+
+	if !strings.HasPrefix(path, host+"/") {
+		return "", "", "", false
+	}
+
+	parts := strings.Split(strings.TrimPrefix(path, host+"/"), "/")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", "", false
+	}
+
+	return parts[0], parts[1], strings.Join(parts[2:], "/"), true
+}
+
+// headLicense looks up the license of the default branch. The license
+// files in dirs are tried first, in order, followed by the license of the
+// repository using the GitHub Repository License API.
+func (f *RepoAPI) headLicense(ctx context.Context, owner, repo string, dirs []string) (*license.License, error) {
+	if len(dirs) > 0 {
+		license.UpdateStatus(ctx, license.StatusNormal, "detecting module license")
+		lic, err := f.detect(ctx, owner, repo, "", dirs)
+		if err != nil && !isNotFound(err) {
+			return nil, err
+		}
+
+		// Without a license file in the module directory, the detector
+		// falls back to other files such as the README, which is usually
+		// inconclusive. The license of the repository is better then.
+		if lic != nil && !lic.Inconclusive() {
+			return lic, nil
+		}
+	}
+
+	var rl *github.RepositoryLicense
+	err := retry(ctx, func() error {
+		license.UpdateStatus(ctx, license.StatusNormal, "querying license")
+
+		var err error
+		rl, _, err = f.Client.Repositories.License(ctx, owner, repo)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// If the license type is "other" then we try to use go-license-detector
+	// to determine the license, which seems to be accurate in these cases.
+	if rl.GetLicense().GetKey() == "other" {
+		return detect(rl, f.Threshold)
+	}
+
+	return &license.License{
+		Name:   rl.GetLicense().GetName(),
+		SPDX:   rl.GetLicense().GetSPDXID(),
+		Source: "GitHub API",
+	}, nil
+}
+
+// detect detects the license from the license files at ref in each of
+// dirs in order, returning the first license found. An empty dir is the
+// root of the repository. Directories that don't exist are skipped, but
+// if the last directory doesn't exist the not found error is returned.
+func (f *RepoAPI) detect(ctx context.Context, owner, repo, ref string, dirs []string) (*license.License, error) {
+	d := detector.Detector{Threshold: f.Threshold}
+	for i, dir := range dirs {
+		lic, err := d.Detect(&contentsFiler{
+			Ctx:    ctx,
+			Client: f.Client,
+			Owner:  owner,
+			Repo:   repo,
+			Ref:    ref,
+			Dir:    dir,
+		})
+		if isNotFound(err) && i < len(dirs)-1 {
+			continue
+		}
+		if lic != nil {
+			lic.Source = "GitHub files at " + ref
+			if ref == "" {
+				lic.Source = "GitHub files at default branch"
+			}
+		}
+		if err != nil || lic != nil {
+			return lic, err
+		}
+	}
+
+	return nil, nil
+}
+
+// retry calls f, waiting and retrying if GitHub rate limits the request.
+func retry(ctx context.Context, f func() error) error {
+	for {
+		err := f()
+		rateErr, ok := err.(*github.RateLimitError)
+		if !ok {
+			return err
+		}
 
-FETCH_RETRY:
-	license.UpdateStatus(ctx, license.StatusNormal, "querying license")
-	rl, _, err := f.Client.Repositories.License(ctx, matches[1], matches[2])
-	if rateErr, ok := err.(*github.RateLimitError); ok {
 		dur := time.Until(rateErr.Rate.Reset.Time)
 		timer := time.NewTimer(dur)
-		defer timer.Stop()
 		license.UpdateStatus(ctx, license.StatusWarning, fmt.Sprintf(
 			"rate limited by GitHub, waiting %s", dur))
 
 		select {
 		case <-ctx.Done():
 			// Context cancelled or ended so return early
-			return nil, ctx.Err()
+			timer.Stop()
+			return ctx.Err()
 
 		case <-timer.C:
 			// Rate limit should be up, retry
-			goto FETCH_RETRY
 		}
 	}
-	if err != nil {
-		return nil, err
+}
+
+// isNotFound returns true if the error is a GitHub API 404 response.
+func isNotFound(err error) bool {
+	errResp, ok := err.(*github.ErrorResponse)
+	return ok && errResp.Response != nil &&
+		errResp.Response.StatusCode == http.StatusNotFound
+}
+
+// sameLicense returns true if the two licenses are the same license.
+func sameLicense(a, b *license.License) bool {
+	if a.SPDX != "" && b.SPDX != "" {
+		return a.SPDX == b.SPDX
 	}
 
-	// If the license type is "other" then we try to use go-license-detector
-	// to determine the license, which seems to be accurate in these cases.
-	if rl.GetLicense().GetKey() == "other" {
-		return detect(rl)
+	return a.Name == b.Name
+}
+
+// licenseID returns the SPDX ID of the license, or the name if the
+// license has no SPDX ID.
+func licenseID(l *license.License) string {
+	if l.SPDX != "" {
+		return l.SPDX
 	}
 
-	return &license.License{
-		Name: rl.GetLicense().GetName(),
-		SPDX: rl.GetLicense().GetSPDXID(),
-	}, nil
+	return l.Name
+}
+
+// withWarning returns a copy of the license with the given warning added.
+func withWarning(l *license.License, warning string) *license.License {
+	if l == nil {
+		return nil
+	}
+
+	result := *l
+	result.Warnings = append(append([]string(nil), l.Warnings...), warning)
+	return &result
 }
 
-// githubRe is the regexp matching the package for a GitHub import.
-var githubRe = regexp.MustCompile(`^github\.com/([^/]+)/([^/]+)$`)
+// majorVersionRe matches the major version suffix of a module directory,
+// which is not part of the tags of the module.
+var majorVersionRe = regexp.MustCompile(`(^|/)v\d+$`)
@@ -0,0 +1,302 @@
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v18/github"
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepoAPI(t *testing.T) {
+	mit := testLicenseText(t, "mit")
+	mpl := testLicenseText(t, "mpl")
+	mitFile := &license.File{Path: "LICENSE", Name: "MIT License", SPDX: "MIT", Confidence: 0.9939759}
+	mplFile := &license.File{Path: "LICENSE", Name: "Mozilla Public License 2.0", SPDX: "MPL-2.0", Confidence: 0.9779316}
+
+	cases := []struct {
+		Name         string
+		Module       module.Module
+		ExactVersion bool
+		Files        map[string]map[string]string // ref => name => content
+		Expected     *license.License
+	}{
+		{
+			"non-github",
+			module.Module{Path: "gitlab.com/foo/bar"},
+			false,
+			nil,
+			nil,
+		},
+
+		{
+			"default branch",
+			module.Module{Path: "github.com/foo/bar", Version: "v1.0.0"},
+			false,
+			nil,
+			&license.License{Name: "MIT License", SPDX: "MIT", Source: "GitHub API"},
+		},
+
+		{
+			"exact version same license",
+			module.Module{Path: "github.com/foo/bar", Version: "v1.0.0"},
+			true,
+			map[string]map[string]string{
+				"v1.0.0": {"LICENSE": mit, "main.go": "package main"},
+			},
+			&license.License{
+				Name:       "MIT License",
+				SPDX:       "MIT",
+				Files:      []*license.File{mitFile},
+				Source:     "GitHub files at v1.0.0",
+				Confidence: 0.9939759,
+			},
+		},
+
+		{
+			"exact version relicensed",
+			module.Module{Path: "github.com/foo/bar", Version: "v0.0.0-20181111172936-0467c0c38ca2"},
+			true,
+			map[string]map[string]string{
+				"0467c0c38ca2": {"COPYING": mpl},
+			},
+			&license.License{
+				Name: "Mozilla Public License 2.0",
+				SPDX: "MPL-2.0",
+				Warnings: []string{
+					`license at 0467c0c38ca2 differs from default branch license "MIT"`,
+				},
+				Files: []*license.File{
+					{Path: "COPYING", Name: "Mozilla Public License 2.0", SPDX: "MPL-2.0", Confidence: 0.9779316},
+				},
+				Source:     "GitHub files at 0467c0c38ca2",
+				Confidence: 0.9779316,
+			},
+		},
+
+		{
+			"exact version missing ref",
+			module.Module{Path: "github.com/foo/bar", Version: "v1.0.0"},
+			true,
+			nil,
+			&license.License{
+				Name: "MIT License",
+				SPDX: "MIT",
+				Warnings: []string{
+					"v1.0.0 not found in repository, using default branch license",
+				},
+				Source: "GitHub API",
+			},
+		},
+
+		{
+			"nested module with license",
+			module.Module{Path: "github.com/foo/bar/api", Version: "v1.2.0"},
+			false,
+			map[string]map[string]string{
+				"": {"LICENSE": mit, "api/LICENSE": mpl, "api/api.go": "package api"},
+			},
+			&license.License{
+				Name:       "Mozilla Public License 2.0",
+				SPDX:       "MPL-2.0",
+				Files:      []*license.File{mplFile},
+				Source:     "GitHub files at default branch",
+				Confidence: 0.9779316,
+			},
+		},
+
+		{
+			"nested module without license",
+			module.Module{Path: "github.com/foo/bar/api", Version: "v1.2.0"},
+			false,
+			map[string]map[string]string{
+				"": {"LICENSE": mit, "api/api.go": "package api"},
+			},
+			&license.License{Name: "MIT License", SPDX: "MIT", Source: "GitHub API"},
+		},
+
+		{
+			"nested module with inconclusive license",
+			module.Module{Path: "github.com/foo/bar/api", Version: "v1.2.0"},
+			false,
+			map[string]map[string]string{
+				"": {"LICENSE": mit, "api/LICENSE": mit[:len(mit)*5/6]},
+			},
+			&license.License{Name: "MIT License", SPDX: "MIT", Source: "GitHub API"},
+		},
+
+		{
+			"nested module major version directory",
+			module.Module{Path: "github.com/foo/bar/api", Version: "v2.0.0"},
+			false,
+			map[string]map[string]string{
+				"": {"LICENSE": mit, "api/v2/LICENSE": mpl},
+			},
+			&license.License{
+				Name:       "Mozilla Public License 2.0",
+				SPDX:       "MPL-2.0",
+				Files:      []*license.File{mplFile},
+				Source:     "GitHub files at default branch",
+				Confidence: 0.9779316,
+			},
+		},
+
+		{
+			"nested module exact version",
+			module.Module{Path: "github.com/foo/bar/api", Version: "v1.2.0"},
+			true,
+			map[string]map[string]string{
+				"api/v1.2.0": {"LICENSE": mit, "api/LICENSE": mpl},
+			},
+			&license.License{
+				Name: "Mozilla Public License 2.0",
+				SPDX: "MPL-2.0",
+				Warnings: []string{
+					`license at api/v1.2.0 differs from default branch license "MIT"`,
+				},
+				Files:      []*license.File{mplFile},
+				Source:     "GitHub files at api/v1.2.0",
+				Confidence: 0.9779316,
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			srv := httptest.NewServer(testGitHubHandler(t, tt.Files))
+			defer srv.Close()
+
+			f := &RepoAPI{Client: testClient(t, srv), ExactVersion: tt.ExactVersion}
+			actual, err := f.License(context.Background(), tt.Module)
+			require.NoError(t, err)
+			require.Equal(t, tt.Expected, actual)
+		})
+	}
+}
+
+func TestRepoAPI_enterprise(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/api/v3/", http.StripPrefix("/api/v3", testGitHubHandler(t, nil)))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client, err := github.NewEnterpriseClient(
+		srv.URL+"/api/v3/", srv.URL+"/api/uploads/", nil)
+	require.NoError(t, err)
+
+	f := &RepoAPI{Client: client, Host: "github.example.com"}
+
+	actual, err := f.License(context.Background(), module.Module{
+		Path: "github.example.com/foo/bar", Version: "v1.0.0"})
+	require.NoError(t, err)
+	require.Equal(t, &license.License{Name: "MIT License", SPDX: "MIT", Source: "GitHub API"}, actual)
+
+	// Modules on the public GitHub aren't looked up on the enterprise host
+	actual, err = f.License(context.Background(), module.Module{
+		Path: "github.com/foo/bar", Version: "v1.0.0"})
+	require.NoError(t, err)
+	require.Nil(t, actual)
+}
+
+// testClient returns a GitHub client that sends requests to the server.
+func testClient(t *testing.T, srv *httptest.Server) *github.Client {
+	u, err := url.Parse(srv.URL + "/")
+	require.NoError(t, err)
+
+	c := github.NewClient(nil)
+	c.BaseURL = u
+	return c
+}
+
+// testGitHubHandler returns a handler that serves the subset of the GitHub
+// API used by RepoAPI for the repository "foo/bar". The default branch
+// license is always MIT. files is the set of files per ref available
+// through the contents API, where the empty ref is the default branch.
+// File names may contain slashes to place them in directories.
+func testGitHubHandler(t *testing.T, files map[string]map[string]string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/foo/bar/license", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"license": map[string]string{
+				"key":     "mit",
+				"name":    "MIT License",
+				"spdx_id": "MIT",
+			},
+		})
+	})
+	mux.HandleFunc("/repos/foo/bar/contents/", func(w http.ResponseWriter, r *http.Request) {
+		fs, ok := files[r.URL.Query().Get("ref")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message": "Not Found"}`))
+			return
+		}
+
+		name := r.URL.Path[len("/repos/foo/bar/contents/"):]
+		if content, ok := fs[name]; ok {
+			json.NewEncoder(w).Encode(map[string]string{
+				"type":     "file",
+				"name":     path.Base(name),
+				"path":     name,
+				"encoding": "base64",
+				"content":  base64.StdEncoding.EncodeToString([]byte(content)),
+			})
+			return
+		}
+
+		// List the direct children of the directory
+		prefix := ""
+		if name != "" {
+			prefix = name + "/"
+		}
+		seen := make(map[string]bool)
+		dir := []map[string]string{}
+		for k := range fs {
+			if !strings.HasPrefix(k, prefix) {
+				continue
+			}
+
+			child, typ := strings.TrimPrefix(k, prefix), "file"
+			if idx := strings.Index(child, "/"); idx >= 0 {
+				child, typ = child[:idx], "dir"
+			}
+			if seen[child] {
+				continue
+			}
+			seen[child] = true
+
+			dir = append(dir, map[string]string{
+				"type": typ,
+				"name": child,
+				"path": prefix + child,
+			})
+		}
+		if len(dir) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message": "Not Found"}`))
+			return
+		}
+
+		json.NewEncoder(w).Encode(dir)
+	})
+
+	return mux
+}
+
+// testLicenseText returns the contents of a license in testdata.
+func testLicenseText(t *testing.T, name string) string {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", name+".txt"))
+	require.NoError(t, err)
+	return string(data)
+}
@@ -0,0 +1,136 @@
+// Package gitlab contains a license.Finder for repositories hosted on
+// GitLab, including self-hosted GitLab instances.
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/license/detector"
+	"github.com/mitchellh/golicense/module"
+)
+
+const (
+	DefaultHost = "gitlab.com"
+	DefaultURL  = "https://gitlab.com/api/v4"
+)
+
+// Finder implements license.Finder and looks up the license of a module
+// using the GitLab Projects API[1], which reports the license detected on
+// the default branch.
+//
+// GitLab supports nested groups, so the project path can't be determined
+// from the import path alone. The full import path is tried first,
+// followed by each parent path down to "group/project".
+//
+// [1]: https://docs.gitlab.com/ee/api/projects.html#get-single-project
+type Finder struct {
+	// Host is the host name that import paths must start with to be looked
+	// up with this finder. This defaults to DefaultHost.
+	Host string
+
+	// URL is the base URL of the GitLab API, including the version. This
+	// defaults to DefaultURL.
+	URL string
+
+	// Token is a personal access token used to authenticate. This is
+	// optional for public projects.
+	Token string
+
+	// Client is the HTTP client to use. If this is nil, http.DefaultClient
+	// is used.
+	Client *http.Client
+}
+
+// project is the subset of the project API response that we use.
+type project struct {
+	License *struct {
+		Key  string `json:"key"`
+		Name string `json:"name"`
+	} `json:"license"`
+}
+
+// License implements license.Finder
+func (f *Finder) License(ctx context.Context, m module.Module) (*license.License, error) {
+	host := f.Host
+	if host == "" {
+		host = DefaultHost
+	}
+
+	if !strings.HasPrefix(m.Path, host+"/") {
+		return nil, nil
+	}
+
+	parts := strings.Split(strings.TrimPrefix(m.Path, host+"/"), "/")
+	for i := len(parts); i >= 2; i-- {
+		path := strings.Join(parts[:i], "/")
+		license.UpdateStatus(ctx, license.StatusNormal, fmt.Sprintf(
+			"querying GitLab project %s", path))
+
+		var p project
+		found, err := f.get(ctx, "/projects/"+url.PathEscape(path)+"?license=true", &p)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+
+		if p.License == nil {
+			return nil, nil
+		}
+
+		lic := &license.License{Name: p.License.Name, Source: "GitLab API"}
+		if id, ok := detector.LookupID(p.License.Key); ok {
+			lic.SPDX = id
+		}
+
+		return lic, nil
+	}
+
+	return nil, nil
+}
+
+// get performs an API request and decodes the JSON response into result.
+// If the API returns a 404, false is returned with no error.
+func (f *Finder) get(ctx context.Context, path string, result interface{}) (bool, error) {
+	base := f.URL
+	if base == "" {
+		base = DefaultURL
+	}
+
+	req, err := http.NewRequest("GET", strings.TrimSuffix(base, "/")+path, nil)
+	if err != nil {
+		return false, err
+	}
+	if f.Token != "" {
+		req.Header.Set("PRIVATE-TOKEN", f.Token)
+	}
+
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, json.NewDecoder(resp.Body).Decode(result)
+
+	case http.StatusNotFound:
+		return false, nil
+
+	default:
+		return false, fmt.Errorf("GitLab API request %s failed: %s", req.URL, resp.Status)
+	}
+}
@@ -0,0 +1,86 @@
+package gitlab
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFinder(t *testing.T) {
+	var token string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/projects/", func(w http.ResponseWriter, r *http.Request) {
+		token = r.Header.Get("PRIVATE-TOKEN")
+		switch r.URL.EscapedPath() {
+		case "/api/v4/projects/group%2Fsubgroup%2Fproject":
+			w.Write([]byte(`{"license": {"key": "apache-2.0", "name": "Apache License 2.0"}}`))
+
+		case "/api/v4/projects/group%2Funlicensed":
+			w.Write([]byte(`{"license": null}`))
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	cases := []struct {
+		Name     string
+		Path     string
+		Expected *license.License
+	}{
+		{
+			"other host",
+			"github.com/group/subgroup/project",
+			nil,
+		},
+
+		{
+			"nested group",
+			"gitlab.example.com/group/subgroup/project",
+			&license.License{Name: "Apache License 2.0", SPDX: "Apache-2.0", Source: "GitLab API"},
+		},
+
+		{
+			"package within project",
+			"gitlab.example.com/group/subgroup/project/api",
+			&license.License{Name: "Apache License 2.0", SPDX: "Apache-2.0", Source: "GitLab API"},
+		},
+
+		{
+			"no license",
+			"gitlab.example.com/group/unlicensed",
+			nil,
+		},
+
+		{
+			"not found",
+			"gitlab.example.com/group/missing",
+			nil,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			f := &Finder{
+				Host:  "gitlab.example.com",
+				URL:   srv.URL + "/api/v4",
+				Token: "secret",
+			}
+
+			actual, err := f.License(context.Background(), module.Module{Path: tt.Path})
+			require.NoError(t, err)
+			require.Equal(t, tt.Expected, actual)
+			if tt.Path != "github.com/group/subgroup/project" {
+				require.Equal(t, "secret", token)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+// Package goproxy contains a license.Finder that detects licenses from
+// module zip files downloaded from a Go module proxy.
+package goproxy
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/license/detector"
+	"github.com/mitchellh/golicense/module"
+)
+
+// Finder implements license.Finder and detects the license of a module
+// by downloading the module zip file from a Go module proxy[1], verifying
+// it against the module hash, and detecting the license from the license
+// files within.
+//
+// This works for modules from any host, not just GitHub, and detects the
+// license of the exact version. Any proxy implementing the module proxy
+// protocol can be used, such as proxy.golang.org or an Athens mirror.
+//
+// [1]: https://golang.org/ref/mod#goproxy-protocol
+type Finder struct {
+	// URL is the base URL of the module proxy, such as
+	// "https://proxy.golang.org".
+	URL string
+
+	// Client is the HTTP client to use. If this is nil, http.DefaultClient
+	// is used.
+	Client *http.Client
+
+	// Threshold is the minimum similarity score for license text to match
+	// a license. See detector.Detector.
+	Threshold float32
+}
+
+// maxZipSize is the maximum size of a module zip file. This matches the
+// limit enforced by the go command.
+const maxZipSize = 500 << 20
+
+// License implements license.Finder
+func (f *Finder) License(ctx context.Context, m module.Module) (*license.License, error) {
+	if m.Version == "" {
+		return nil, nil
+	}
+
+	for _, p := range m.ModulePaths() {
+		license.UpdateStatus(ctx, license.StatusNormal, "downloading module from proxy")
+		data, err := f.download(ctx, p, m.Version)
+		if err != nil {
+			return nil, err
+		}
+		if data == nil {
+			continue
+		}
+
+		z, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("error reading module zip for %s@%s: %s", p, m.Version, err)
+		}
+
+		// Verify the zip. If there is no hash to verify against then we
+		// still detect the license but warn about it.
+		var warnings []string
+		switch {
+		case strings.HasPrefix(m.Hash, "h1:"):
+			sum, err := hashZip(z)
+			if err != nil {
+				return nil, err
+			}
+			if sum != m.Hash {
+				return nil, fmt.Errorf(
+					"module zip for %s@%s hash mismatch: expected %s, got %s",
+					p, m.Version, m.Hash, sum)
+			}
+
+		case m.Hash == "":
+			warnings = append(warnings, "module zip not verified, binary has no module hash")
+
+		default:
+			warnings = append(warnings, fmt.Sprintf(
+				"module zip not verified, unsupported hash %q", m.Hash))
+		}
+
+		license.UpdateStatus(ctx, license.StatusNormal, "detecting license in module zip")
+		d := detector.Detector{Threshold: f.Threshold}
+		lic, err := d.Detect(newZipFiler(z, p+"@"+m.Version+"/"))
+		if err != nil {
+			return nil, err
+		}
+		if lic != nil {
+			lic.Source = "module proxy"
+			lic.Warnings = append(lic.Warnings, warnings...)
+		}
+
+		return lic, nil
+	}
+
+	return nil, nil
+}
+
+// download downloads the module zip for the given module path and version.
+// If the proxy doesn't have the module, nil is returned with no error.
+func (f *Finder) download(ctx context.Context, path, version string) ([]byte, error) {
+	url := fmt.Sprintf("%s/%s/@v/%s.zip",
+		strings.TrimSuffix(f.URL, "/"),
+		module.EscapePath(path),
+		module.EscapePath(version))
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+
+	case http.StatusNotFound, http.StatusGone:
+		// The module proxy protocol uses these to say the module doesn't
+		// exist, so we try other paths or finders.
+		return nil, nil
+
+	default:
+		return nil, fmt.Errorf("error downloading %s: %s", url, resp.Status)
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxZipSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxZipSize {
+		return nil, fmt.Errorf("module zip %s is larger than %d bytes", url, maxZipSize)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,95 @@
+package goproxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFinder(t *testing.T) {
+	// The testdata directory is laid out like a module proxy
+	srv := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	defer srv.Close()
+
+	cases := []struct {
+		Name     string
+		Module   module.Module
+		Expected *license.License
+		Err      string
+	}{
+		{
+			"verified",
+			module.Module{
+				Path:    "github.com/hashicorp/go-multierror",
+				Version: "v1.0.0",
+				Hash:    "h1:iVjPR7a6H0tWELX5NxNe7bYopibicUzc7uPribsnS6o=",
+			},
+			&license.License{
+				Name:       "Mozilla Public License 2.0",
+				SPDX:       "MPL-2.0",
+				Files:      []*license.File{{Path: "LICENSE", Name: "Mozilla Public License 2.0", SPDX: "MPL-2.0", Confidence: 0.9779316}},
+				Source:     "module proxy",
+				Confidence: 0.9779316,
+			},
+			"",
+		},
+
+		{
+			"no hash",
+			module.Module{
+				Path:    "github.com/hashicorp/go-multierror",
+				Version: "v1.0.0",
+			},
+			&license.License{
+				Name:       "Mozilla Public License 2.0",
+				SPDX:       "MPL-2.0",
+				Warnings:   []string{"module zip not verified, binary has no module hash"},
+				Files:      []*license.File{{Path: "LICENSE", Name: "Mozilla Public License 2.0", SPDX: "MPL-2.0", Confidence: 0.9779316}},
+				Source:     "module proxy",
+				Confidence: 0.9779316,
+			},
+			"",
+		},
+
+		{
+			"hash mismatch",
+			module.Module{
+				Path:    "github.com/hashicorp/go-multierror",
+				Version: "v1.0.0",
+				Hash:    "h1:zVF4y5ciA/rw779S62bEAq4Yif1cBc/UwRkXJ2xZyT4=",
+			},
+			nil,
+			"hash mismatch",
+		},
+
+		{
+			"not found",
+			module.Module{
+				Path:    "github.com/hashicorp/go-multierror",
+				Version: "v1.1.0",
+			},
+			nil,
+			"",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			f := &Finder{URL: srv.URL}
+			actual, err := f.License(context.Background(), tt.Module)
+			if tt.Err != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.Err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.Expected, actual)
+		})
+	}
+}
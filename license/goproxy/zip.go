@@ -0,0 +1,120 @@
+package goproxy
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/src-d/go-license-detector.v2/licensedb/filer"
+)
+
+// hashZip returns the "h1:" hash of a module zip file. This is the same
+// hash that is recorded in go.sum and embedded in binaries: the SHA-256
+// of a summary listing the SHA-256 of every file, sorted by name.
+func hashZip(z *zip.Reader) (string, error) {
+	files := make([]*zip.File, len(z.File))
+	copy(files, z.File)
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+
+	summary := sha256.New()
+	for _, f := range files {
+		if strings.Contains(f.Name, "\n") {
+			return "", fmt.Errorf("module zip contains file name with newline: %q", f.Name)
+		}
+
+		r, err := f.Open()
+		if err != nil {
+			return "", err
+		}
+
+		h := sha256.New()
+		_, err = io.Copy(h, r)
+		r.Close()
+		if err != nil {
+			return "", err
+		}
+
+		fmt.Fprintf(summary, "%x  %s\n", h.Sum(nil), f.Name)
+	}
+
+	return "h1:" + base64.StdEncoding.EncodeToString(summary.Sum(nil)), nil
+}
+
+// zipFiler implements filer.Filer for the files within a module zip.
+// Module zips contain every file under a "path@version/" prefix which is
+// treated as the root.
+type zipFiler struct {
+	files map[string]*zip.File
+}
+
+func newZipFiler(z *zip.Reader, prefix string) *zipFiler {
+	files := make(map[string]*zip.File)
+	for _, f := range z.File {
+		if strings.HasPrefix(f.Name, prefix) {
+			files[strings.TrimPrefix(f.Name, prefix)] = f
+		}
+	}
+
+	return &zipFiler{files: files}
+}
+
+func (f *zipFiler) ReadFile(name string) ([]byte, error) {
+	zf, ok := f.files[name]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+
+	r, err := zf.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return ioutil.ReadAll(r)
+}
+
+func (f *zipFiler) ReadDir(dir string) ([]filer.File, error) {
+	prefix := ""
+	if dir != "" {
+		prefix = strings.TrimSuffix(dir, "/") + "/"
+	}
+
+	// Zip files have no directory entries so we infer directories from
+	// the paths of the files within them.
+	seen := make(map[string]bool)
+	var result []filer.File
+	for name := range f.files {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		rest := strings.TrimPrefix(name, prefix)
+		isDir := false
+		if idx := strings.Index(rest, "/"); idx >= 0 {
+			rest = rest[:idx]
+			isDir = true
+		}
+		if rest == "" || seen[rest] {
+			continue
+		}
+
+		seen[rest] = true
+		result = append(result, filer.File{Name: rest, IsDir: isDir})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result, nil
+}
+
+func (f *zipFiler) Close() {}
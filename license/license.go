@@ -3,9 +3,58 @@ package license
 //go:generate mockery -all -inpkg
 
 // License represents a software license.
+//
+// A module covered by multiple licenses is represented by a single License
+// where SPDX is an SPDX license expression such as "MIT OR Apache-2.0".
 type License struct {
 	Name string // Name is a human-friendly name like "MIT License"
-	SPDX string // SPDX ID of the license, blank if unknown or unavailable
+	SPDX string // SPDX ID or expression, blank if unknown or unavailable
+
+	// Warnings are notes about the detected license that should be brought
+	// to the attention of the user, such as the license of the module
+	// version differing from the license of the latest source.
+	Warnings []string
+
+	// Files are the licenses detected in each license file of the module,
+	// if the license was detected from the license files. This is empty if
+	// the license came from an API or an override.
+	Files []*File
+
+	// Source describes where the license came from, such as "GitHub API"
+	// or "module cache". This is set by the Finder.
+	Source string
+
+	// Confidence is the similarity score from 0 to 1 of the license text
+	// to the detected license, if the license was detected from license
+	// text. If multiple files were detected, this is the lowest score.
+	// This is zero if the source doesn't report a score, such as an API.
+	Confidence float32
+
+	// Translations are the module paths that the module was translated to,
+	// in order, before the license was found. This is empty if the license
+	// was found using the original module path.
+	Translations []string
+
+	// Candidates are the closest matching licenses, highest score first,
+	// if the license text couldn't be matched with confidence. This is
+	// set if no license matched above the detection threshold, in which
+	// case SPDX is blank, or if several licenses matched closely, in which
+	// case SPDX is the best match.
+	Candidates []*Candidate
+}
+
+// Candidate is a license that closely matched license text.
+type Candidate struct {
+	SPDX       string  // SPDX ID of the license
+	Confidence float32 // Confidence is the similarity score from 0 to 1
+}
+
+// File is a license detected in a single license file.
+type File struct {
+	Path       string  // Path is the path to the file relative to the module root
+	Name       string  // Name is a human-friendly name like "MIT License"
+	SPDX       string  // SPDX ID of the license
+	Confidence float32 // Confidence is the similarity score from 0 to 1
 }
 
 func (l *License) String() string {
@@ -15,3 +64,9 @@ func (l *License) String() string {
 
 	return l.Name
 }
+
+// Inconclusive returns true if license text was found but didn't match
+// any license with confidence. The closest matches are in Candidates.
+func (l *License) Inconclusive() bool {
+	return l != nil && l.SPDX == "" && len(l.Candidates) > 0
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/mitchellh/go-spdx"
 	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/license/expr"
 	"github.com/mitchellh/golicense/module"
 )
 
@@ -13,6 +14,11 @@ import (
 // given mapping if the path exists in the map.
 type Finder struct {
 	Map map[string]string
+
+	// Offline disables looking up the names of licenses on spdx.org. If
+	// this is true, the override value is used as both the name and the
+	// SPDX ID.
+	Offline bool
 }
 
 // License implements license.Finder
@@ -22,11 +28,23 @@ func (f *Finder) License(ctx context.Context, m module.Module) (*license.License
 		return nil, nil
 	}
 
+	// Expressions such as "MIT OR Apache-2.0" or "GPL-2.0+" can't be
+	// looked up so the expression is used as the name.
+	if e, err := expr.Parse(v); err == nil {
+		if l, ok := e.(*expr.License); !ok || l.OrLater || l.Exception != "" {
+			return &license.License{Name: v, SPDX: e.String(), Source: "override"}, nil
+		}
+	}
+
+	if f.Offline {
+		return &license.License{Name: v, SPDX: v, Source: "override"}, nil
+	}
+
 	// Look up the license by SPDX ID
 	lic, err := spdx.License(v)
 	if err != nil {
 		return nil, fmt.Errorf("Override license %q SPDX lookup error: %s", v, err)
 	}
 
-	return &license.License{Name: lic.Name, SPDX: lic.ID}, nil
+	return &license.License{Name: lic.Name, SPDX: lic.ID, Source: "override"}, nil
 }
@@ -0,0 +1,69 @@
+package mapper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFinder(t *testing.T) {
+	// None of these cases look up the license on spdx.org
+	cases := []struct {
+		Name   string
+		Finder *Finder
+		Path   string
+		Result *license.License
+	}{
+		{
+			"not in the map",
+			&Finder{},
+			"github.com/foo/bar",
+			nil,
+		},
+
+		{
+			"expression",
+			&Finder{Map: map[string]string{"github.com/foo/bar": "MIT OR Apache-2.0"}},
+			"github.com/foo/bar",
+			&license.License{Name: "MIT OR Apache-2.0", SPDX: "MIT OR Apache-2.0", Source: "override"},
+		},
+
+		{
+			"exception",
+			&Finder{Map: map[string]string{
+				"github.com/foo/bar": "GPL-2.0 WITH Classpath-exception-2.0",
+			}},
+			"github.com/foo/bar",
+			&license.License{
+				Name:   "GPL-2.0 WITH Classpath-exception-2.0",
+				SPDX:   "GPL-2.0 WITH Classpath-exception-2.0",
+				Source: "override",
+			},
+		},
+
+		{
+			"or later",
+			&Finder{Map: map[string]string{"github.com/foo/bar": "GPL-2.0+"}},
+			"github.com/foo/bar",
+			&license.License{Name: "GPL-2.0+", SPDX: "GPL-2.0+", Source: "override"},
+		},
+
+		{
+			"offline",
+			&Finder{Map: map[string]string{"github.com/foo/bar": "MIT"}, Offline: true},
+			"github.com/foo/bar",
+			&license.License{Name: "MIT", SPDX: "MIT", Source: "override"},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			actual, err := tt.Finder.License(context.Background(), module.Module{Path: tt.Path})
+			require.NoError(t, err)
+			require.Equal(t, tt.Result, actual)
+		})
+	}
+}
@@ -0,0 +1,87 @@
+// Package modcache contains a license.Finder that detects licenses from
+// the module sources in the local Go module cache.
+package modcache
+
+import (
+	"context"
+	"fmt"
+	"go/build"
+	"os"
+	"path/filepath"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/license/detector"
+	"github.com/mitchellh/golicense/module"
+	"gopkg.in/src-d/go-license-detector.v2/licensedb/filer"
+)
+
+// Finder implements license.Finder and detects the license of a module
+// from the license files of the exact module version in the local Go
+// module cache. This never accesses the network.
+//
+// If the module version isn't in the module cache then no license is
+// returned so that other finders can be tried.
+type Finder struct {
+	// Dir is the module cache directory. If this is empty, GOMODCACHE is
+	// used if set, otherwise "pkg/mod" within the first GOPATH entry.
+	Dir string
+
+	// Threshold is the minimum similarity score for license text to match
+	// a license. See detector.Detector.
+	Threshold float32
+}
+
+// License implements license.Finder
+func (f *Finder) License(ctx context.Context, m module.Module) (*license.License, error) {
+	if m.Version == "" {
+		return nil, nil
+	}
+
+	root := f.Dir
+	if root == "" {
+		root = Dir()
+	}
+
+	for _, p := range m.ModulePaths() {
+		dir := filepath.Join(root, filepath.FromSlash(
+			module.EscapePath(p)+"@"+module.EscapePath(m.Version)))
+		if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+			continue
+		}
+
+		license.UpdateStatus(ctx, license.StatusNormal, "detecting license in module cache")
+		fs, err := filer.FromDirectory(dir)
+		if err != nil {
+			return nil, err
+		}
+		defer fs.Close()
+
+		d := detector.Detector{Offline: true, Threshold: f.Threshold}
+		lic, err := d.Detect(fs)
+		if err != nil {
+			return nil, fmt.Errorf("error detecting license in %s: %s", dir, err)
+		}
+		if lic != nil {
+			lic.Source = "module cache"
+		}
+
+		return lic, nil
+	}
+
+	return nil, nil
+}
+
+// Dir returns the default module cache directory. This is GOMODCACHE if
+// set, otherwise "pkg/mod" within the first GOPATH entry.
+func Dir() string {
+	if v := os.Getenv("GOMODCACHE"); v != "" {
+		return v
+	}
+
+	gopath := build.Default.GOPATH
+	if list := filepath.SplitList(gopath); len(list) > 0 {
+		gopath = list[0]
+	}
+
+	return filepath.Join(gopath, "pkg", "mod")
+}
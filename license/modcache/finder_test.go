@@ -0,0 +1,76 @@
+package modcache
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFinder(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Module   module.Module
+		Expected *license.License
+	}{
+		{
+			"case encoded path",
+			module.Module{Path: "github.com/MitchellH/example", Version: "v1.0.0"},
+			&license.License{
+				Name:       "MIT License",
+				SPDX:       "MIT",
+				Files:      []*license.File{{Path: "LICENSE", Name: "MIT License", SPDX: "MIT", Confidence: 0.9939759}},
+				Source:     "module cache",
+				Confidence: 0.9939759,
+			},
+		},
+
+		{
+			"major version suffix",
+			module.Module{Path: "github.com/foo/bar", Version: "v2.1.0"},
+			&license.License{
+				Name:       "Mozilla Public License 2.0",
+				SPDX:       "MPL-2.0",
+				Files:      []*license.File{{Path: "LICENSE", Name: "Mozilla Public License 2.0", SPDX: "MPL-2.0", Confidence: 0.9779316}},
+				Source:     "module cache",
+				Confidence: 0.9779316,
+			},
+		},
+
+		{
+			"version not in cache",
+			module.Module{Path: "github.com/foo/bar", Version: "v2.2.0"},
+			nil,
+		},
+
+		{
+			"no license files",
+			module.Module{Path: "github.com/foo/empty", Version: "v1.0.0"},
+			nil,
+		},
+
+		{
+			"no version",
+			module.Module{Path: "github.com/foo/empty"},
+			nil,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			f := &Finder{Dir: "testdata"}
+			actual, err := f.License(context.Background(), tt.Module)
+			require.NoError(t, err)
+			require.Equal(t, tt.Expected, actual)
+		})
+	}
+}
+
+func TestDir(t *testing.T) {
+	defer os.Setenv("GOMODCACHE", os.Getenv("GOMODCACHE"))
+	os.Setenv("GOMODCACHE", "/tmp/modcache")
+	require.Equal(t, "/tmp/modcache", Dir())
+}
@@ -5,27 +5,36 @@ import (
 	"flag"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/google/go-github/v18/github"
-	"github.com/rsc/goversion/version"
 	"golang.org/x/oauth2"
 
 	"github.com/mitchellh/golicense/config"
 	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/license/bitbucket"
+	"github.com/mitchellh/golicense/license/cache"
 	githubFinder "github.com/mitchellh/golicense/license/github"
+	"github.com/mitchellh/golicense/license/gitlab"
 	"github.com/mitchellh/golicense/license/golang"
 	"github.com/mitchellh/golicense/license/gopkg"
+	"github.com/mitchellh/golicense/license/goproxy"
 	"github.com/mitchellh/golicense/license/mapper"
+	"github.com/mitchellh/golicense/license/modcache"
 	"github.com/mitchellh/golicense/license/resolver"
 	"github.com/mitchellh/golicense/module"
 )
 
 const (
-	EnvGitHubToken = "GITHUB_TOKEN"
+	EnvGitHubToken           = "GITHUB_TOKEN"
+	EnvGitHubEnterpriseToken = "GITHUB_ENTERPRISE_TOKEN"
+	EnvGitLabToken           = "GITLAB_TOKEN"
+	EnvBitbucketToken        = "BITBUCKET_TOKEN"
 )
 
 func main() {
@@ -36,22 +45,72 @@ func realMain() int {
 	termOut := &TermOutput{Out: os.Stdout}
 
 	var flagLicense bool
+	var flagOffline bool
+	var flagExactVersion bool
+	var flagGoProxy string
+	var flagCacheDir string
+	var flagCacheTTL time.Duration
+	var flagGitHub, flagGitLab, flagBitbucket config.Host
 	var flagOutXLSX string
+	var flagOutJSON string
+	var flagOutSPDX string
+	var flagOutCycloneDX string
+	var flagDiff string
 	flags := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
 	flags.BoolVar(&flagLicense, "license", true,
 		"look up and verify license. If false, dependencies are\n"+
 			"printed without licenses.")
+	flags.BoolVar(&flagOffline, "offline", false,
+		"only look up licenses without network access, using overrides\n"+
+			"and the local Go module cache.")
+	flags.BoolVar(&flagExactVersion, "exact-version", false,
+		"detect GitHub licenses at the exact version of each dependency\n"+
+			"rather than the default branch, warning if they differ.")
+	flags.StringVar(&flagGoProxy, "goproxy", "",
+		"Go module proxy URL to download dependencies from to detect\n"+
+			"licenses, such as https://proxy.golang.org")
+	flags.StringVar(&flagCacheDir, "cache-dir", "",
+		"directory to cache license lookups in. If empty, lookups are\n"+
+			"not cached.")
+	flags.DurationVar(&flagCacheTTL, "cache-ttl", 24*time.Hour,
+		"how long cached license lookups are valid for, 0 to never expire")
+	flags.StringVar(&flagGitHub.Host, "github-host", "",
+		"host name of GitHub Enterprise import paths, defaults to the host of -github-url")
+	flags.StringVar(&flagGitHub.URL, "github-url", "",
+		"base URL of the GitHub Enterprise API, such as https://github.example.com/api/v3/")
+	flags.StringVar(&flagGitLab.Host, "gitlab-host", "",
+		"host name of GitLab import paths, for self-hosted GitLab")
+	flags.StringVar(&flagGitLab.URL, "gitlab-url", "",
+		"base URL of the GitLab API, for self-hosted GitLab")
+	flags.StringVar(&flagBitbucket.Host, "bitbucket-host", "",
+		"host name of Bitbucket import paths, for a Bitbucket Cloud API proxy")
+	flags.StringVar(&flagBitbucket.URL, "bitbucket-url", "",
+		"base URL of the Bitbucket Cloud API. Bitbucket Server isn't supported")
 	flags.BoolVar(&termOut.Plain, "plain", false, "plain terminal output, no colors or live updates")
-	flags.BoolVar(&termOut.Verbose, "verbose", false, "additional logging to terminal, requires -plain")
+	flags.BoolVar(&termOut.Verbose, "verbose", false,
+		"show where each license came from, and with -plain also log\n"+
+			"all status updates")
 	flags.StringVar(&flagOutXLSX, "out-xlsx", "",
 		"save report in Excel XLSX format to the given path")
+	flags.StringVar(&flagOutJSON, "out-json", "",
+		"save report in JSON format to the given path")
+	flags.StringVar(&flagOutSPDX, "out-spdx", "",
+		"save an SPDX SBOM to the given path. The format is JSON if the\n"+
+			"path ends in \".json\", otherwise tag-value.")
+	flags.StringVar(&flagOutCycloneDX, "out-cyclonedx", "",
+		"save a CycloneDX BOM to the given path. The format is XML if the\n"+
+			"path ends in \".xml\", otherwise JSON.")
+	flags.StringVar(&flagDiff, "diff", "",
+		"only check modules that changed since the given baseline and report\n"+
+			"the changes. The baseline is any input, or a report saved with\n"+
+			"-out-json if the path ends in \".json\".")
 	flags.Parse(os.Args[1:])
 	args := flags.Args()
 	if len(args) == 0 {
 		fmt.Fprintf(os.Stderr, color.RedString(
 			"❗️ Path to file to analyze expected.\n\n"))
 		printHelp(flags)
-		return 1
+		return ExitInput
 	}
 
 	// Determine the exe path and parse the configuration if given.
@@ -64,70 +123,156 @@ func realMain() int {
 		if err != nil {
 			fmt.Fprintf(os.Stderr, color.RedString(fmt.Sprintf(
 				"❗️ Error parsing configuration:\n\n%s\n", err)))
-			return 1
+			return ExitInput
 		}
 
 		// Store the config and set it on the output
 		cfg = *c
 	}
 
-	allMods := map[module.Module]struct{}{}
+	// Modules are deduplicated by moduleKey, so the same version of a
+	// module is only looked up once. Every module of every input is kept
+	// in inputMods to group the versions of each module across inputs.
+	allMods := map[string]module.Module{}
+	var inputMods []module.Module
+	builds := map[string]*module.BuildInfo{}
+	modBinaries := map[string][]string{}
+	var binPaths []string
 	for _, exePath := range exePaths {
-		// Read the dependencies from the binary itself
-		vsn, err := version.ReadExe(exePath)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, color.RedString(fmt.Sprintf(
-				"❗️ Error reading %q: %s\n", args[0], err)))
-			return 1
+		ins, ok := readInputsOrExit(exePath)
+		if !ok {
+			return ExitInput
 		}
-
-		if vsn.ModuleInfo == "" {
-			// ModuleInfo empty means that the binary didn't use Go modules
-			// or it could mean that a binary has no dependencies. Either way
-			// we error since we can't be sure.
-			fmt.Fprintf(os.Stderr, color.YellowString(fmt.Sprintf(
-				"⚠️  %q ⚠️\n\n"+
-					"This executable was compiled without using Go modules or has \n"+
-					"zero dependencies. golicense considers this an error (exit code 1).\n", exePath)))
-			return 1
+		for _, in := range ins {
+			binPaths = append(binPaths, in.Path)
+			for _, mod := range in.Modules {
+				key := moduleKey(&mod)
+				allMods[key] = mod
+				modBinaries[key] = append(modBinaries[key], in.Path)
+			}
+			inputMods = append(inputMods, in.Modules...)
+			if in.Build != nil {
+				builds[in.Path] = in.Build
+			}
 		}
+	}
 
-		// From the raw module string from the binary, we need to parse this
-		// into structured data with the module information.
-		mods, err := module.ParseExeData(vsn.ModuleInfo)
+	mods := make([]module.Module, 0, len(allMods))
+	for _, mod := range allMods {
+		mods = append(mods, mod)
+	}
+
+	// In diff mode only the modules that are new or changed version since
+	// the baseline are checked and printed. The reports still list every
+	// module, so they're complete and can be used as the next baseline.
+	var base *baseline
+	var diffOut *DiffOutput
+	var changed map[string]bool
+	checkMods := mods
+	if flagDiff != "" {
+		var err error
+		base, err = readBaseline(flagDiff)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, color.RedString(fmt.Sprintf(
-				"❗️ Error parsing dependencies: %s\n", err)))
-			return 1
+				"❗️ Error reading baseline %q: %s\n", flagDiff, err)))
+			return ExitInput
+		}
+
+		diffOut = &DiffOutput{
+			Out:      os.Stdout,
+			Baseline: flagDiff,
+			Changes:  module.Diff(base.Modules, mods),
 		}
-		for _, mod := range mods {
-			allMods[mod] = struct{}{}
+
+		checkMods = nil
+		changed = make(map[string]bool)
+		for _, c := range diffOut.Changes {
+			if c.New != nil {
+				checkMods = append(checkMods, *c.New)
+				changed[moduleKey(c.New)] = true
+			}
+			if c.Old != nil && base.Results != nil {
+				if r, ok := base.Results[moduleKey(c.Old)]; ok {
+					diffOut.SetBaseline(c.Old, r.License, r.Err)
+				}
+			}
 		}
 	}
 
-	mods := make([]module.Module, 0, len(allMods))
-	for mod := range allMods {
-		mods = append(mods, mod)
-	}
+	// Group the versions of each module across inputs to report skew
+	groups := module.GroupByPath(inputMods)
 
 	// Complete terminal output setup
 	termOut.Config = &cfg
-	termOut.Modules = mods
-
-	// Setup the outputs
-	out := &MultiOutput{Outputs: []Output{termOut}}
+	termOut.Modules = checkMods
+	termOut.Binaries = binPaths
+	termOut.ModuleBinaries = modBinaries
+	termOut.Groups = groups
+
+	// Setup the outputs. The reports are written to files and receive
+	// every module, even in diff mode.
+	reports := &MultiOutput{}
 	if flagOutXLSX != "" {
-		out.Outputs = append(out.Outputs, &XLSXOutput{
-			Path:   flagOutXLSX,
-			Config: &cfg,
+		reports.Outputs = append(reports.Outputs, &XLSXOutput{
+			Path:           flagOutXLSX,
+			Config:         &cfg,
+			Binaries:       binPaths,
+			ModuleBinaries: modBinaries,
+		})
+	}
+	if flagOutJSON != "" {
+		reports.Outputs = append(reports.Outputs, &JSONOutput{
+			Path:           flagOutJSON,
+			Config:         &cfg,
+			Builds:         builds,
+			ModuleBinaries: modBinaries,
+			Groups:         groups,
+		})
+	}
+	if flagOutSPDX != "" {
+		reports.Outputs = append(reports.Outputs, &SPDXOutput{
+			Path:           flagOutSPDX,
+			Binaries:       binPaths,
+			ModuleBinaries: modBinaries,
+		})
+	}
+	if flagOutCycloneDX != "" {
+		reports.Outputs = append(reports.Outputs, &CycloneDXOutput{
+			Path:           flagOutCycloneDX,
+			Binaries:       binPaths,
+			ModuleBinaries: modBinaries,
 		})
 	}
+	out := &MultiOutput{Outputs: []Output{termOut, reports}}
+	if diffOut != nil {
+		out.Outputs = append(out.Outputs, diffOut)
+	}
 
 	// Setup a context. We don't connect this to an interrupt signal or
 	// anything since we just exit immediately on interrupt. No cleanup
 	// necessary.
 	ctx := context.Background()
 
+	// Flags take priority over the configuration for hosts
+	for _, h := range []struct {
+		Flag *config.Host
+		Cfg  **config.Host
+	}{
+		{&flagGitHub, &cfg.GitHub},
+		{&flagGitLab, &cfg.GitLab},
+		{&flagBitbucket, &cfg.Bitbucket},
+	} {
+		if *h.Cfg == nil {
+			*h.Cfg = &config.Host{}
+		}
+		if h.Flag.Host != "" {
+			(*h.Cfg).Host = h.Flag.Host
+		}
+		if h.Flag.URL != "" {
+			(*h.Cfg).URL = h.Flag.URL
+		}
+	}
+
 	// Auth with GitHub if available
 	var githubClient *http.Client
 	if v := os.Getenv(EnvGitHubToken); v != "" {
@@ -135,29 +280,160 @@ func realMain() int {
 		githubClient = oauth2.NewClient(ctx, ts)
 	}
 
-	// Build our translators and license finders
-	ts := []license.Translator{
-		&mapper.Translator{Map: cfg.Translate},
-		&resolver.Translator{},
-		&golang.Translator{},
-		&gopkg.Translator{},
+	// Setup GitHub Enterprise if configured. If no host is given then
+	// import paths are expected to use the host of the API URL.
+	var githubEnterprise *github.Client
+	if cfg.GitHub.URL != "" {
+		u, err := url.Parse(cfg.GitHub.URL)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, color.RedString(fmt.Sprintf(
+				"❗️ Error parsing GitHub Enterprise URL: %s\n", err)))
+			return ExitInput
+		}
+		if cfg.GitHub.Host == "" {
+			cfg.GitHub.Host = u.Hostname()
+		}
+
+		var client *http.Client
+		if v := os.Getenv(EnvGitHubEnterpriseToken); v != "" {
+			ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: v})
+			client = oauth2.NewClient(ctx, ts)
+		}
+
+		githubEnterprise, err = github.NewEnterpriseClient(
+			cfg.GitHub.URL, cfg.GitHub.URL, client)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, color.RedString(fmt.Sprintf(
+				"❗️ Error configuring GitHub Enterprise: %s\n", err)))
+			return ExitInput
+		}
+	}
+
+	// Build our translators and license finders. The module cache finder
+	// is tried right after overrides since it is local and detects the
+	// license of the exact version in the binary. In offline mode we skip
+	// everything that requires network access.
+	ts := []license.Translator{&mapper.Translator{Map: cfg.Translate}}
+	if !flagOffline {
+		ts = append(ts, &resolver.Translator{})
+	}
+	ts = append(ts, &golang.Translator{}, &gopkg.Translator{})
+
+	// cached wraps a finder with the on-disk cache if it is enabled. The
+	// key must identify the finder and any settings that change results.
+	cached := func(f license.Finder, key string) license.Finder {
+		if flagCacheDir == "" {
+			return f
+		}
+
+		// The detection threshold changes the results of every finder
+		// that detects licenses from license text.
+		if cfg.Threshold != 0 {
+			key += fmt.Sprintf(":threshold=%g", cfg.Threshold)
+		}
+
+		return &cache.Finder{
+			Finder: f,
+			Key:    key,
+			Dir:    flagCacheDir,
+			TTL:    flagCacheTTL,
+		}
 	}
+
 	var fs []license.Finder
 	if flagLicense {
 		fs = []license.Finder{
-			&mapper.Finder{Map: cfg.Override},
-			&githubFinder.RepoAPI{
-				Client: github.NewClient(githubClient),
-			},
+			&mapper.Finder{Map: cfg.Override, Offline: flagOffline},
+			&modcache.Finder{Threshold: cfg.Threshold},
+		}
+		if !flagOffline && flagGoProxy != "" {
+			fs = append(fs, cached(&goproxy.Finder{URL: flagGoProxy, Threshold: cfg.Threshold},
+				"goproxy:"+flagGoProxy))
+		}
+		if !flagOffline {
+			key := "github"
+			if flagExactVersion {
+				key = "github-exact"
+			}
+
+			fs = append(fs, cached(&githubFinder.RepoAPI{
+				Client:       github.NewClient(githubClient),
+				ExactVersion: flagExactVersion,
+				Threshold:    cfg.Threshold,
+			}, key))
+			if githubEnterprise != nil {
+				fs = append(fs, cached(&githubFinder.RepoAPI{
+					Client:       githubEnterprise,
+					Host:         cfg.GitHub.Host,
+					ExactVersion: flagExactVersion,
+					Threshold:    cfg.Threshold,
+				}, key+":"+cfg.GitHub.URL))
+			}
+			fs = append(fs, cached(&gitlab.Finder{
+				Host:  cfg.GitLab.Host,
+				URL:   cfg.GitLab.URL,
+				Token: os.Getenv(EnvGitLabToken),
+			}, "gitlab:"+cfg.GitLab.URL))
+			fs = append(fs, cached(&bitbucket.Finder{
+				Host:      cfg.Bitbucket.Host,
+				URL:       cfg.Bitbucket.URL,
+				Token:     os.Getenv(EnvBitbucketToken),
+				Threshold: cfg.Threshold,
+			}, "bitbucket:"+cfg.Bitbucket.URL))
+		}
+	}
+
+	// lookup finds the license of a single module. We first try the
+	// untranslated version. If we can detect a license then take that.
+	// Otherwise, we translate.
+	lookup := func(ctx context.Context, m module.Module) (*license.License, error) {
+		lic, err := license.Find(ctx, m, fs)
+		if lic == nil || err != nil || lic.Inconclusive() {
+			tm, chain := license.TranslateChain(ctx, m, ts)
+			tlic, terr := license.Find(ctx, tm, fs)
+			if tlic != nil && len(chain) > 0 {
+				// Copy so we don't modify a cached result
+				copy := *tlic
+				copy.Translations = chain
+				tlic = &copy
+			}
+
+			// An inconclusive license is only replaced by a better one
+			if !lic.Inconclusive() || (tlic != nil && !tlic.Inconclusive()) {
+				lic, err = tlic, terr
+			}
+		}
+
+		return lic, err
+	}
+
+	// lookupModule finds the license of a module with lookup, taking the
+	// replacement into account. See lookupReplaced.
+	lookupModule := func(ctx context.Context, m module.Module) (*license.License, error) {
+		if m.Replace == nil {
+			return lookup(ctx, m)
 		}
+
+		return lookupReplaced(ctx, m, lookup)
 	}
 
 	// Kick off all the license lookups.
 	var wg sync.WaitGroup
 	sem := NewSemaphore(5)
 	for _, m := range mods {
+		// Modules that didn't change since the baseline are only looked
+		// up for the reports, if there are any.
+		o := Output(out)
+		if changed != nil && !changed[moduleKey(&m)] {
+			if len(reports.Outputs) == 0 {
+				continue
+			}
+
+			o = reports
+		}
+
 		wg.Add(1)
-		go func(m module.Module) {
+		go func(m module.Module, out Output) {
 			defer wg.Done()
 
 			// Acquire a semaphore so that we can limit concurrency
@@ -169,15 +445,30 @@ func realMain() int {
 
 			// Lookup
 			out.Start(&m)
+			lic, err := lookupModule(ctx, m)
+			out.Finish(&m, lic, err)
+		}(m, o)
+	}
 
-			// We first try the untranslated version. If we can detect
-			// a license then take that. Otherwise, we translate.
-			lic, err := license.Find(ctx, m, fs)
-			if lic == nil || err != nil {
-				lic, err = license.Find(ctx, license.Translate(ctx, m, ts), fs)
+	// If the baseline isn't a report then we also need the licenses of the
+	// previous versions of changed modules to report license changes.
+	if diffOut != nil && base.Results == nil {
+		for _, c := range diffOut.Changes {
+			if c.Added() || c.Removed() {
+				continue
 			}
-			out.Finish(&m, lic, err)
-		}(m)
+
+			wg.Add(1)
+			go func(m module.Module) {
+				defer wg.Done()
+
+				sem.Acquire()
+				defer sem.Release()
+
+				lic, err := lookupModule(ctx, m)
+				diffOut.SetBaseline(&m, lic, err)
+			}(*c.Old)
+		}
 	}
 
 	// Wait for all lookups to complete
@@ -187,12 +478,37 @@ func realMain() int {
 	if err := out.Close(); err != nil {
 		fmt.Fprintf(os.Stderr, color.RedString(fmt.Sprintf(
 			"❗️ Error: %s\n", err)))
-		return 1
+		return ExitInput
 	}
 
 	return termOut.ExitCode()
 }
 
+// readInputsOrExit reads the dependencies of an input with readInputs,
+// printing any error. If this returns false, the program should exit.
+func readInputsOrExit(path string) ([]*input, bool) {
+	ins, err := readInputs(path)
+	if err == errNoModuleInfo {
+		fmt.Fprintf(os.Stderr, color.YellowString(fmt.Sprintf(
+			"⚠️  %q ⚠️\n\n"+
+				"This executable was compiled without using Go modules or has \n"+
+				"zero dependencies. golicense considers this an error (exit code 2).\n", path)))
+		return nil, false
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, color.RedString(fmt.Sprintf(
+			"❗️ Error reading %q: %s\n", path, err)))
+		return nil, false
+	}
+	for _, in := range ins {
+		for _, w := range in.Warnings {
+			fmt.Fprintln(os.Stderr, color.YellowString("⚠️  %s: %s", in.Path, w))
+		}
+	}
+
+	return ins, true
+}
+
 func printHelp(fs *flag.FlagSet) {
 	fmt.Fprintf(os.Stderr, strings.TrimSpace(help)+"\n\n", os.Args[0])
 	fs.PrintDefaults()
@@ -201,13 +517,24 @@ func printHelp(fs *flag.FlagSet) {
 const help = `
 golicense analyzes the dependencies of a binary compiled from Go.
 
-Usage: %[1]s [flags] [BINARY]
-Usage: %[1]s [flags] [CONFIG] [BINARY]
+Usage: %[1]s [flags] [INPUT]
+Usage: %[1]s [flags] [CONFIG] [INPUT...]
 
-One or two arguments can be given: a binary by itself which will output
-all the licenses of dependencies, or a configuration file and a binary
+One or two arguments can be given: an input by itself which will output
+all the licenses of dependencies, or a configuration file and inputs
 which also notes which licenses are allowed among other settings.
 
+An input is a binary compiled from Go, a go.mod file or a directory
+containing one, a file with the output of "go list -m -json all", a
+tar or zip archive or container image (from "docker save") containing
+binaries compiled from Go, or any other directory, which is searched
+recursively for binaries and archives.
+
+The exit code is 0 if all modules passed, 1 if any license is denied, 2
+if an input or the configuration couldn't be read, 3 if any license is
+unknown or missing, and 4 if any license lookup failed. The "fail_on"
+setting of the configuration sets which of these fail the run.
+
 For full help text, see the README in the GitHub repository:
 http://github.com/mitchellh/golicense
 
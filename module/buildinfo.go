@@ -0,0 +1,99 @@
+package module
+
+import (
+	"debug/buildinfo"
+	"io"
+	"runtime/debug"
+	"strings"
+)
+
+// BuildInfo is the build information embedded in a binary compiled from
+// Go with module support.
+type BuildInfo struct {
+	GoVersion string // GoVersion is the Go version used to build, such as "go1.21.0"
+	Path      string // Path is the package path of the main package
+	Main      Module // Main is the main module, with version "(devel)" for local builds
+
+	// Deps are the dependencies of the main module. Modules replaced with
+	// a replace directive have Replace set to the module that was built.
+	Deps []Module
+
+	// Settings are the build settings, such as "GOOS", "GOARCH",
+	// "CGO_ENABLED", "-tags" and "vcs.revision". Older toolchains don't
+	// record any settings.
+	Settings map[string]string
+}
+
+// ReadBuildInfo reads the build information of the binary at path.
+func ReadBuildInfo(path string) (*BuildInfo, error) {
+	info, err := buildinfo.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return newBuildInfo(info), nil
+}
+
+// ReadBuildInfoFrom reads the build information of the binary in r, such
+// as a binary read from an archive.
+func ReadBuildInfoFrom(r io.ReaderAt) (*BuildInfo, error) {
+	info, err := buildinfo.Read(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return newBuildInfo(info), nil
+}
+
+// newBuildInfo converts build information from the standard library.
+func newBuildInfo(info *debug.BuildInfo) *BuildInfo {
+	result := &BuildInfo{
+		GoVersion: info.GoVersion,
+		Path:      info.Path,
+		Main:      Module{Path: info.Main.Path, Version: info.Main.Version, Hash: info.Main.Sum},
+		Settings:  make(map[string]string),
+	}
+
+	for _, d := range info.Deps {
+		m := Module{Path: stripImportVersion(d.Path), Version: d.Version, Hash: d.Sum}
+		if r := d.Replace; r != nil {
+			m.Replace = &Module{Path: r.Path, Version: r.Version, Hash: r.Sum}
+			if r.Version != "" {
+				m.Replace.Path = stripImportVersion(r.Path)
+			}
+		}
+
+		result.Deps = append(result.Deps, m)
+	}
+
+	for _, s := range info.Settings {
+		result.Settings[s.Key] = s.Value
+	}
+
+	return result
+}
+
+// GOOS returns the target operating system, or an empty string if it
+// wasn't recorded.
+func (b *BuildInfo) GOOS() string { return b.Settings["GOOS"] }
+
+// GOARCH returns the target architecture, or an empty string if it
+// wasn't recorded.
+func (b *BuildInfo) GOARCH() string { return b.Settings["GOARCH"] }
+
+// CGOEnabled returns true if the binary was built with cgo enabled.
+func (b *BuildInfo) CGOEnabled() bool { return b.Settings["CGO_ENABLED"] == "1" }
+
+// Tags returns the build tags the binary was built with.
+func (b *BuildInfo) Tags() []string {
+	v := b.Settings["-tags"]
+	if v == "" {
+		return nil
+	}
+
+	return strings.Split(v, ",")
+}
+
+// VCSRevision returns the version control revision of the main module, or
+// an empty string if it wasn't recorded.
+func (b *BuildInfo) VCSRevision() string { return b.Settings["vcs.revision"] }
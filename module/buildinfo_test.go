@@ -0,0 +1,87 @@
+package module
+
+import (
+	"os"
+	"runtime"
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadBuildInfo(t *testing.T) {
+	// The test binary itself is built with module support
+	path, err := os.Executable()
+	require.NoError(t, err)
+
+	info, err := ReadBuildInfo(path)
+	require.NoError(t, err)
+	require.Equal(t, runtime.Version(), info.GoVersion)
+	require.Equal(t, "github.com/mitchellh/golicense", info.Main.Path)
+	require.Equal(t, runtime.GOOS, info.GOOS())
+	require.Equal(t, runtime.GOARCH, info.GOARCH())
+
+	_, err = ReadBuildInfo("buildinfo.go")
+	require.Error(t, err)
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	info, err = ReadBuildInfoFrom(f)
+	require.NoError(t, err)
+	require.Equal(t, "github.com/mitchellh/golicense", info.Main.Path)
+}
+
+func TestNewBuildInfo(t *testing.T) {
+	info := newBuildInfo(&debug.BuildInfo{
+		GoVersion: "go1.21.0",
+		Path:      "github.com/foo/bar/cmd/bar",
+		Main:      debug.Module{Path: "github.com/foo/bar", Version: "(devel)"},
+		Deps: []*debug.Module{
+			{Path: "github.com/fatih/color", Version: "v1.7.0", Sum: "h1:color"},
+			{
+				Path:    "gopkg.in/yaml.v2",
+				Version: "v2.2.1",
+				Sum:     "h1:yaml",
+				Replace: &debug.Module{Path: "github.com/ourorg/yaml", Version: "v2.2.2", Sum: "h1:fork"},
+			},
+			{
+				Path:    "github.com/google/go-github/v18",
+				Version: "v18.2.0",
+				Replace: &debug.Module{Path: "../go-github"},
+			},
+		},
+		Settings: []debug.BuildSetting{
+			{Key: "-tags", Value: "netgo,osusergo"},
+			{Key: "CGO_ENABLED", Value: "0"},
+			{Key: "GOARCH", Value: "arm64"},
+			{Key: "GOOS", Value: "linux"},
+			{Key: "vcs.revision", Value: "0467c0c38ca2"},
+		},
+	})
+
+	require.Equal(t, "go1.21.0", info.GoVersion)
+	require.Equal(t, "github.com/foo/bar/cmd/bar", info.Path)
+	require.Equal(t, Module{Path: "github.com/foo/bar", Version: "(devel)"}, info.Main)
+	require.Equal(t, []Module{
+		{Path: "github.com/fatih/color", Version: "v1.7.0", Hash: "h1:color"},
+		{
+			Path:    "gopkg.in/yaml.v2",
+			Version: "v2.2.1",
+			Hash:    "h1:yaml",
+			Replace: &Module{Path: "github.com/ourorg/yaml", Version: "v2.2.2", Hash: "h1:fork"},
+		},
+		{
+			Path:    "github.com/google/go-github",
+			Version: "v18.2.0",
+			Replace: &Module{Path: "../go-github"},
+		},
+	}, info.Deps)
+
+	require.Equal(t, "linux", info.GOOS())
+	require.Equal(t, "arm64", info.GOARCH())
+	require.False(t, info.CGOEnabled())
+	require.Equal(t, []string{"netgo", "osusergo"}, info.Tags())
+	require.Equal(t, "0467c0c38ca2", info.VCSRevision())
+}
@@ -0,0 +1,119 @@
+package module
+
+import "sort"
+
+// Change is the change of a single module between two sets of modules.
+// Old is nil if the module was added and New is nil if the module was
+// removed. Otherwise the versions or replacements differ.
+type Change struct {
+	Path string
+	Old  *Module
+	New  *Module
+}
+
+// Added returns true if the module was added.
+func (c *Change) Added() bool { return c.Old == nil }
+
+// Removed returns true if the module was removed.
+func (c *Change) Removed() bool { return c.New == nil }
+
+// Diff returns the modules that were added, removed or changed version or
+// replacement from old to new, sorted by path and version.
+//
+// Modules are matched by path. If a set has several versions of the same
+// path, every version is compared: versions in both sets are unchanged,
+// and the remaining versions are paired up from highest to lowest as
+// changes. Any versions left over are added or removed.
+func Diff(old, new []Module) []*Change {
+	oldIndex, newIndex := byPath(old), byPath(new)
+
+	var result []*Change
+	for p, os := range oldIndex {
+		os, ns := without(os, newIndex[p]), without(newIndex[p], os)
+		for i := 0; i < len(os) || i < len(ns); i++ {
+			c := &Change{Path: p}
+			if i < len(os) {
+				c.Old = os[i]
+			}
+			if i < len(ns) {
+				c.New = ns[i]
+			}
+
+			result = append(result, c)
+		}
+	}
+	for p, ns := range newIndex {
+		if _, ok := oldIndex[p]; ok {
+			continue
+		}
+
+		for _, n := range ns {
+			result = append(result, &Change{Path: p, New: n})
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+
+		return CompareVersions(a.version(), b.version()) > 0
+	})
+
+	return result
+}
+
+// version returns the version used to sort changes: the new version, or
+// the old version for removed modules.
+func (c *Change) version() string {
+	if c.New != nil {
+		return c.New.Version
+	}
+
+	return c.Old.Version
+}
+
+// byPath returns the modules in ms by path, without duplicates, sorted by
+// version from highest to lowest. Modules are duplicates if they have the
+// same version and replacement.
+func byPath(ms []Module) map[string][]*Module {
+	result := make(map[string][]*Module, len(ms))
+	seen := make(map[string]struct{}, len(ms))
+	for i := range ms {
+		m := &ms[i]
+		if _, ok := seen[m.String()]; ok {
+			continue
+		}
+		seen[m.String()] = struct{}{}
+
+		result[m.Path] = append(result[m.Path], m)
+	}
+
+	for _, vs := range result {
+		sort.SliceStable(vs, func(i, j int) bool {
+			return CompareVersions(vs[i].Version, vs[j].Version) > 0
+		})
+	}
+
+	return result
+}
+
+// without returns the modules of a that aren't in b, keeping the order.
+func without(a, b []*Module) []*Module {
+	var result []*Module
+	for _, m := range a {
+		found := false
+		for _, v := range b {
+			if v.String() == m.String() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, m)
+		}
+	}
+
+	return result
+}
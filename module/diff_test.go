@@ -0,0 +1,99 @@
+package module
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	old := []Module{
+		{Path: "github.com/a/same", Version: "v1.0.0"},
+		{Path: "github.com/b/removed", Version: "v1.0.0"},
+		{Path: "github.com/c/changed", Version: "v1.0.0"},
+		{Path: "github.com/d/multi", Version: "v1.2.0"},
+		{Path: "github.com/d/multi", Version: "v1.1.0"},
+		{Path: "github.com/f/forked", Version: "v1.0.0"},
+	}
+	new := []Module{
+		{Path: "github.com/a/same", Version: "v1.0.0"},
+		{Path: "github.com/c/changed", Version: "v1.1.0"},
+		{Path: "github.com/d/multi", Version: "v1.2.0"},
+		{Path: "github.com/e/added", Version: "v0.1.0"},
+		{
+			Path:    "github.com/f/forked",
+			Version: "v1.0.0",
+			Replace: &Module{Path: "github.com/ourorg/forked", Version: "v1.0.1"},
+		},
+	}
+
+	actual := Diff(old, new)
+	require.Equal(t, []*Change{
+		{
+			Path: "github.com/b/removed",
+			Old:  &Module{Path: "github.com/b/removed", Version: "v1.0.0"},
+		},
+		{
+			Path: "github.com/c/changed",
+			Old:  &Module{Path: "github.com/c/changed", Version: "v1.0.0"},
+			New:  &Module{Path: "github.com/c/changed", Version: "v1.1.0"},
+		},
+		{
+			Path: "github.com/d/multi",
+			Old:  &Module{Path: "github.com/d/multi", Version: "v1.1.0"},
+		},
+		{
+			Path: "github.com/e/added",
+			New:  &Module{Path: "github.com/e/added", Version: "v0.1.0"},
+		},
+		{
+			Path: "github.com/f/forked",
+			Old:  &Module{Path: "github.com/f/forked", Version: "v1.0.0"},
+			New: &Module{
+				Path:    "github.com/f/forked",
+				Version: "v1.0.0",
+				Replace: &Module{Path: "github.com/ourorg/forked", Version: "v1.0.1"},
+			},
+		},
+	}, actual)
+
+	require.True(t, actual[0].Removed())
+	require.False(t, actual[1].Added() || actual[1].Removed())
+	require.True(t, actual[3].Added())
+
+	require.Empty(t, Diff(old, old))
+}
+
+func TestDiff_multipleVersions(t *testing.T) {
+	old := []Module{
+		{Path: "github.com/a/multi", Version: "v1.1.0"},
+		{Path: "github.com/a/multi", Version: "v1.3.0"},
+		{Path: "github.com/a/multi", Version: "v2.0.0"},
+	}
+	new := []Module{
+		{Path: "github.com/a/multi", Version: "v1.2.0"},
+		{Path: "github.com/a/multi", Version: "v2.0.0"},
+		{Path: "github.com/a/multi", Version: "v2.0.0"},
+		{Path: "github.com/a/multi", Version: "v2.1.0"},
+		{Path: "github.com/a/multi", Version: "v1.4.0"},
+	}
+
+	// v2.0.0 is unchanged and the other versions are paired from highest
+	// to lowest, leaving one version added.
+	require.Equal(t, []*Change{
+		{
+			Path: "github.com/a/multi",
+			Old:  &Module{Path: "github.com/a/multi", Version: "v1.3.0"},
+			New:  &Module{Path: "github.com/a/multi", Version: "v2.1.0"},
+		},
+		{
+			Path: "github.com/a/multi",
+			Old:  &Module{Path: "github.com/a/multi", Version: "v1.1.0"},
+			New:  &Module{Path: "github.com/a/multi", Version: "v1.4.0"},
+		},
+		{
+			Path: "github.com/a/multi",
+			New:  &Module{Path: "github.com/a/multi", Version: "v1.2.0"},
+		},
+	}, Diff(old, new))
+}
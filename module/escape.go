@@ -0,0 +1,63 @@
+package module
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// EscapePath escapes a module path or version for use in the file system
+// or a module proxy URL. Every uppercase letter is replaced with an
+// exclamation mark followed by the lowercase letter so that the result
+// is safe on case-insensitive file systems.
+func EscapePath(p string) string {
+	var b strings.Builder
+	for _, r := range p {
+		if unicode.IsUpper(r) {
+			b.WriteByte('!')
+			r = unicode.ToLower(r)
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// ModulePaths returns the possible full module paths for the module.
+//
+// ParseExeData strips the major version suffix such as "/v2" from the
+// path, so for modules at major version 2 or above this returns the path
+// with the suffix restored first, followed by Path. Otherwise this only
+// returns Path.
+func (m *Module) ModulePaths() []string {
+	result := []string{m.Path}
+	if strings.HasSuffix(m.Version, "+incompatible") ||
+		strings.HasPrefix(m.Path, "gopkg.in/") ||
+		importVersionRe.MatchString(m.Path) {
+		return result
+	}
+
+	major := m.Version
+	if idx := strings.IndexAny(major, ".-+"); idx >= 0 {
+		major = major[:idx]
+	}
+	if !strings.HasPrefix(major, "v") {
+		return result
+	}
+
+	n, err := strconv.Atoi(major[1:])
+	if err != nil || n < 2 {
+		return result
+	}
+
+	return append([]string{fmt.Sprintf("%s/v%d", m.Path, n)}, result...)
+}
+
+// FullPath returns the module path including the major version suffix
+// that ParseExeData strips, such as "github.com/google/go-github/v18".
+// This is the first of ModulePaths.
+func (m *Module) FullPath() string {
+	return m.ModulePaths()[0]
+}
@@ -0,0 +1,75 @@
+package module
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEscapePath(t *testing.T) {
+	cases := []struct {
+		Input  string
+		Output string
+	}{
+		{"github.com/mitchellh/golicense", "github.com/mitchellh/golicense"},
+		{"github.com/BurntSushi/toml", "github.com/!burnt!sushi/toml"},
+		{"v1.0.0-RC1", "v1.0.0-!r!c1"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Input, func(t *testing.T) {
+			require.Equal(t, tt.Output, EscapePath(tt.Input))
+		})
+	}
+}
+
+func TestModuleModulePaths(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Module   Module
+		Expected []string
+	}{
+		{
+			"v1",
+			Module{Path: "github.com/foo/bar", Version: "v1.2.3"},
+			[]string{"github.com/foo/bar"},
+		},
+
+		{
+			"v12",
+			Module{Path: "github.com/rsc/goversion", Version: "v12.0.0"},
+			[]string{"github.com/rsc/goversion/v12", "github.com/rsc/goversion"},
+		},
+
+		{
+			"pseudo-version",
+			Module{Path: "github.com/foo/bar", Version: "v2.0.0-20181111172936-0467c0c38ca2"},
+			[]string{"github.com/foo/bar/v2", "github.com/foo/bar"},
+		},
+
+		{
+			"incompatible",
+			Module{Path: "github.com/foo/bar", Version: "v2.1.0+incompatible"},
+			[]string{"github.com/foo/bar"},
+		},
+
+		{
+			"gopkg.in",
+			Module{Path: "gopkg.in/yaml.v2", Version: "v2.2.1"},
+			[]string{"gopkg.in/yaml.v2"},
+		},
+
+		{
+			"no version",
+			Module{Path: "github.com/foo/bar"},
+			[]string{"github.com/foo/bar"},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			require.Equal(t, tt.Expected, tt.Module.ModulePaths())
+			require.Equal(t, tt.Expected[0], tt.Module.FullPath())
+		})
+	}
+}
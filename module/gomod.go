@@ -0,0 +1,239 @@
+package module
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ParseGoMod parses the dependencies from the contents of a go.mod file.
+// The hashes of the dependencies are taken from the contents of the go.sum
+// file, which may be nil.
+//
+// Replace directives are recorded as the Replace of the module they
+// apply to.
+func ParseGoMod(mod, sum []byte) ([]Module, error) {
+	var requires []Module
+	replaces := make(map[string]Module)
+
+	block := ""
+	scanner := bufio.NewScanner(bytes.NewReader(mod))
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if idx := strings.Index(line, "//"); idx >= 0 {
+			line = line[:idx]
+		}
+
+		fields, err := goModFields(line)
+		if err != nil {
+			return nil, fmt.Errorf("go.mod:%d: %s", n, err)
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		// Blocks such as "require (" apply the verb to each line until ")"
+		verb := block
+		switch {
+		case block != "" && fields[0] == ")":
+			block = ""
+			continue
+
+		case block == "" && len(fields) == 2 && fields[1] == "(":
+			block = fields[0]
+			continue
+
+		case block == "":
+			verb, fields = fields[0], fields[1:]
+		}
+
+		switch verb {
+		case "require":
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("go.mod:%d: expected module path and version", n)
+			}
+
+			requires = append(requires, Module{Path: fields[0], Version: fields[1]})
+
+		case "replace":
+			// The old version is optional: "old [version] => new [version]"
+			idx := indexOf(fields, "=>")
+			if idx < 1 || idx > 2 || len(fields)-idx-1 < 1 || len(fields)-idx-1 > 2 {
+				return nil, fmt.Errorf("go.mod:%d: invalid replace directive", n)
+			}
+
+			// A local directory replacement has no version
+			key := strings.Join(fields[:idx], "@")
+			replaces[key] = Module{Path: fields[idx+1]}
+			if len(fields)-idx-1 == 2 {
+				replaces[key] = Module{Path: fields[idx+1], Version: fields[idx+2]}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	hashes := parseGoSum(sum)
+	result := make([]Module, 0, len(requires))
+	for _, m := range requires {
+		r, ok := replaces[m.Path+"@"+m.Version]
+		if !ok {
+			r, ok = replaces[m.Path]
+		}
+		if ok && r.Version != "" {
+			r.Hash = hashes[r.Path+"@"+r.Version]
+			r.Path = stripImportVersion(r.Path)
+		}
+		if ok {
+			m.Replace = &r
+		}
+
+		m.Hash = hashes[m.Path+"@"+m.Version]
+		m.Path = stripImportVersion(m.Path)
+		result = append(result, m)
+	}
+
+	return result, nil
+}
+
+// GoModComplete returns true if the go.mod file lists every module that
+// the main module needs, which is the case if its "go" directive is Go
+// 1.17 or later. Earlier go.mod files only list the direct dependencies
+// and some indirect dependencies, so ParseGoMod can't find all modules.
+func GoModComplete(mod []byte) bool {
+	for _, line := range strings.Split(string(mod), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != "go" {
+			continue
+		}
+
+		parts := strings.SplitN(fields[1], ".", 3)
+		if len(parts) < 2 {
+			return false
+		}
+
+		// Prereleases such as "1.21rc1" have a suffix after the minor
+		// version.
+		minor := parts[1]
+		if idx := strings.IndexFunc(minor, func(r rune) bool {
+			return r < '0' || r > '9'
+		}); idx >= 0 {
+			minor = minor[:idx]
+		}
+		major, err1 := strconv.Atoi(parts[0])
+		n, err2 := strconv.Atoi(minor)
+		if err1 != nil || err2 != nil {
+			return false
+		}
+
+		return major > 1 || (major == 1 && n >= 17)
+	}
+
+	return false
+}
+
+// ParseGoList parses the dependencies from the output of
+// "go list -m -json all". The main module is skipped.
+func ParseGoList(r io.Reader) ([]Module, error) {
+	type listModule struct {
+		Path    string
+		Version string
+		Main    bool
+		Sum     string
+		Replace *listModule
+	}
+
+	var result []Module
+	dec := json.NewDecoder(r)
+	for {
+		var m listModule
+		err := dec.Decode(&m)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error parsing go list output: %s", err)
+		}
+		if m.Main {
+			continue
+		}
+
+		next := Module{
+			Path:    stripImportVersion(m.Path),
+			Version: m.Version,
+			Hash:    m.Sum,
+		}
+		if r := m.Replace; r != nil {
+			next.Replace = &Module{Path: r.Path, Version: r.Version, Hash: r.Sum}
+			if r.Version != "" {
+				next.Replace.Path = stripImportVersion(r.Path)
+			}
+		}
+
+		result = append(result, next)
+	}
+
+	return result, nil
+}
+
+// parseGoSum returns the module hashes in the contents of a go.sum file
+// keyed by "path@version". Hashes of go.mod files are skipped.
+func parseGoSum(sum []byte) map[string]string {
+	result := make(map[string]string)
+	for _, line := range strings.Split(string(sum), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 || strings.HasSuffix(fields[1], "/go.mod") {
+			continue
+		}
+
+		result[fields[0]+"@"+fields[1]] = fields[2]
+	}
+
+	return result
+}
+
+// goModFields splits a line of a go.mod file into fields, unquoting any
+// quoted fields.
+func goModFields(line string) ([]string, error) {
+	var result []string
+	for _, f := range strings.Fields(line) {
+		if strings.HasPrefix(f, `"`) || strings.HasPrefix(f, "`") {
+			v, err := strconv.Unquote(f)
+			if err != nil {
+				return nil, fmt.Errorf("invalid quoted string %s", f)
+			}
+
+			f = v
+		}
+
+		result = append(result, f)
+	}
+
+	return result, nil
+}
+
+// indexOf returns the index of v in vs, or -1.
+func indexOf(vs []string, v string) int {
+	for i, s := range vs {
+		if s == v {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// stripImportVersion strips the major version suffix such as "/v2" from a
+// module path. See ModulePaths.
+func stripImportVersion(p string) string {
+	if loc := importVersionRe.FindStringIndex(p); loc != nil {
+		return p[:loc[0]]
+	}
+
+	return p
+}
@@ -0,0 +1,144 @@
+package module
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGoMod(t *testing.T) {
+	mod := `
+module github.com/foo/bar
+
+go 1.13
+
+require github.com/fatih/color v1.7.0
+
+require (
+	github.com/mattn/go-isatty v0.0.4 // indirect
+	github.com/google/go-github/v18 v18.2.0
+	gopkg.in/yaml.v2 v2.2.1
+	github.com/foo/local v1.0.0
+)
+
+replace gopkg.in/yaml.v2 => github.com/ourorg/yaml v2.2.2+incompatible
+
+replace (
+	github.com/foo/local => ../local
+	github.com/mattn/go-isatty v0.0.3 => github.com/mattn/go-isatty v0.0.5
+)
+`
+
+	sum := `
+github.com/fatih/color v1.7.0 h1:DkWD4oS2D8LGGgTQ6IvwJJXSL5Vp2ffcQg58nFV38Ys=
+github.com/fatih/color v1.7.0/go.mod h1:Zm6kSWBoL9eyXnKyktHP6abPY2pDugNf5KwzbycvMj4=
+github.com/google/go-github/v18 v18.2.0 h1:s7Y4I8ZlIL1Ofxpj4AQRJcSGAr0Jl2AHThHgXpsUCfU=
+`
+
+	actual, err := ParseGoMod([]byte(mod), []byte(sum))
+	require.NoError(t, err)
+	require.Equal(t, []Module{
+		{
+			Path:    "github.com/fatih/color",
+			Version: "v1.7.0",
+			Hash:    "h1:DkWD4oS2D8LGGgTQ6IvwJJXSL5Vp2ffcQg58nFV38Ys=",
+		},
+		{Path: "github.com/mattn/go-isatty", Version: "v0.0.4"},
+		{
+			Path:    "github.com/google/go-github",
+			Version: "v18.2.0",
+			Hash:    "h1:s7Y4I8ZlIL1Ofxpj4AQRJcSGAr0Jl2AHThHgXpsUCfU=",
+		},
+		{
+			Path:    "gopkg.in/yaml.v2",
+			Version: "v2.2.1",
+			Replace: &Module{Path: "github.com/ourorg/yaml", Version: "v2.2.2+incompatible"},
+		},
+		{
+			Path:    "github.com/foo/local",
+			Version: "v1.0.0",
+			Replace: &Module{Path: "../local"},
+		},
+	}, actual)
+
+	_, err = ParseGoMod([]byte("require github.com/foo/bar"), nil)
+	require.Error(t, err)
+
+	_, err = ParseGoMod([]byte("replace github.com/foo/bar"), nil)
+	require.Error(t, err)
+}
+
+func TestGoModComplete(t *testing.T) {
+	cases := []struct {
+		Mod      string
+		Expected bool
+	}{
+		{"module foo", false},
+		{"module foo\ngo 1.13", false},
+		{"module foo\ngo 1.16 // comment", false},
+		{"module foo\ngo 1.17", true},
+		{"module foo\n\ngo 1.21.0\ntoolchain go1.21.5", true},
+		{"module foo\ngo 1.22rc1", true},
+		{"module foo\ngo invalid", false},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Mod, func(t *testing.T) {
+			require.Equal(t, tt.Expected, GoModComplete([]byte(tt.Mod)))
+		})
+	}
+}
+
+func TestParseGoList(t *testing.T) {
+	input := `
+{
+	"Path": "github.com/foo/bar",
+	"Main": true,
+	"Dir": "/src/bar"
+}
+{
+	"Path": "github.com/fatih/color",
+	"Version": "v1.7.0",
+	"Sum": "h1:DkWD4oS2D8LGGgTQ6IvwJJXSL5Vp2ffcQg58nFV38Ys="
+}
+{
+	"Path": "gopkg.in/yaml.v2",
+	"Version": "v2.2.1",
+	"Replace": {
+		"Path": "github.com/ourorg/yaml",
+		"Version": "v2.2.2+incompatible"
+	}
+}
+{
+	"Path": "github.com/foo/local",
+	"Version": "v1.0.0",
+	"Replace": {
+		"Path": "../local"
+	}
+}
+`
+
+	actual, err := ParseGoList(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Equal(t, []Module{
+		{
+			Path:    "github.com/fatih/color",
+			Version: "v1.7.0",
+			Hash:    "h1:DkWD4oS2D8LGGgTQ6IvwJJXSL5Vp2ffcQg58nFV38Ys=",
+		},
+		{
+			Path:    "gopkg.in/yaml.v2",
+			Version: "v2.2.1",
+			Replace: &Module{Path: "github.com/ourorg/yaml", Version: "v2.2.2+incompatible"},
+		},
+		{
+			Path:    "github.com/foo/local",
+			Version: "v1.0.0",
+			Replace: &Module{Path: "../local"},
+		},
+	}, actual)
+
+	_, err = ParseGoList(strings.NewReader("{"))
+	require.Error(t, err)
+}
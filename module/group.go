@@ -0,0 +1,77 @@
+package module
+
+import "sort"
+
+// Group is the modules with the same path from several sets of modules,
+// such as the dependencies of several binaries.
+type Group struct {
+	Path string
+
+	// Modules are the distinct modules with the path, lowest version
+	// first. Modules are distinct if their versions, replacements or
+	// hashes differ.
+	Modules []Module
+}
+
+// Skew returns true if the group has more than one version of the module,
+// including different replacements of the same version.
+func (g *Group) Skew() bool {
+	for _, m := range g.Modules[1:] {
+		if m.String() != g.Modules[0].String() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HashMismatch returns true if the group has modules with the same
+// version and replacement but different hashes, which means that the
+// source of the module differs between the sets.
+func (g *Group) HashMismatch() bool {
+	seen := make(map[string]string)
+	for _, m := range g.Modules {
+		if h, ok := seen[m.String()]; ok && h != m.Hash {
+			return true
+		}
+
+		seen[m.String()] = m.Hash
+	}
+
+	return false
+}
+
+// GroupByPath groups modules by path, sorted by path. Duplicate modules
+// are removed.
+func GroupByPath(ms []Module) []*Group {
+	index := make(map[string]*Group)
+	seen := make(map[string]struct{})
+	var result []*Group
+	for _, m := range ms {
+		key := m.String() + " " + m.Hash
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		g, ok := index[m.Path]
+		if !ok {
+			g = &Group{Path: m.Path}
+			index[m.Path] = g
+			result = append(result, g)
+		}
+
+		g.Modules = append(g.Modules, m)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+	for _, g := range result {
+		sort.SliceStable(g.Modules, func(i, j int) bool {
+			return CompareVersions(g.Modules[i].Version, g.Modules[j].Version) < 0
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,48 @@
+package module
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupByPath(t *testing.T) {
+	fork := &Module{Path: "github.com/ourorg/c", Version: "v1.0.1"}
+	groups := GroupByPath([]Module{
+		{Path: "github.com/b/skew", Version: "v1.10.0"},
+		{Path: "github.com/a/same", Version: "v1.0.0", Hash: "h1:a"},
+		{Path: "github.com/b/skew", Version: "v1.2.0"},
+		{Path: "github.com/a/same", Version: "v1.0.0", Hash: "h1:a"},
+		{Path: "github.com/c/replaced", Version: "v1.0.0"},
+		{Path: "github.com/c/replaced", Version: "v1.0.0", Replace: fork},
+		{Path: "github.com/d/hash", Version: "v1.0.0", Hash: "h1:a"},
+		{Path: "github.com/d/hash", Version: "v1.0.0", Hash: "h1:b"},
+	})
+
+	cases := []struct {
+		Path         string
+		Modules      int
+		Skew         bool
+		HashMismatch bool
+	}{
+		{"github.com/a/same", 1, false, false},
+		{"github.com/b/skew", 2, true, false},
+		{"github.com/c/replaced", 2, true, false},
+		{"github.com/d/hash", 2, false, true},
+	}
+
+	require.Len(t, groups, len(cases))
+	for i, tt := range cases {
+		t.Run(tt.Path, func(t *testing.T) {
+			g := groups[i]
+			require.Equal(t, tt.Path, g.Path)
+			require.Len(t, g.Modules, tt.Modules)
+			require.Equal(t, tt.Skew, g.Skew())
+			require.Equal(t, tt.HashMismatch, g.HashMismatch())
+		})
+	}
+
+	// Versions are sorted by precedence, not as strings
+	require.Equal(t, "v1.2.0", groups[1].Modules[0].Version)
+	require.Equal(t, "v1.10.0", groups[1].Modules[1].Version)
+}
@@ -15,11 +15,56 @@ type Module struct {
 	Path    string // Import path, such as "github.com/mitchellh/golicense"
 	Version string // Version like "v1.2.3"
 	Hash    string // Hash such as "h1:abcd1234"
+
+	// Replace is the module that replaced this module with a replace
+	// directive, if any. A replacement with a local directory has the
+	// directory as the Path and no Version.
+	Replace *Module
 }
 
 // String returns a human readable string format.
 func (m *Module) String() string {
-	return fmt.Sprintf("%s (%s)", m.Path, m.Version)
+	result := fmt.Sprintf("%s (%s)", m.Path, m.Version)
+	if m.LocalReplace() {
+		result += " => " + m.Replace.Path
+	} else if m.Replace != nil {
+		result += " => " + m.Replace.String()
+	}
+
+	return result
+}
+
+// Source returns the module whose source was built: the replacement if
+// the module was replaced by another module version, otherwise the module
+// itself. Modules replaced by a local directory return the module itself
+// since the directory isn't a module version that can be looked up. The
+// result never has a replacement.
+func (m *Module) Source() Module {
+	if m.Replace != nil && !m.LocalReplace() {
+		return Module{Path: m.Replace.Path, Version: m.Replace.Version, Hash: m.Replace.Hash}
+	}
+
+	return Module{Path: m.Path, Version: m.Version, Hash: m.Hash}
+}
+
+// LocalReplace returns true if the module was replaced by a local
+// directory.
+func (m *Module) LocalReplace() bool {
+	return m.Replace != nil && m.Replace.Version == ""
+}
+
+// Ref returns the version control ref for the module version. For
+// pseudo-versions such as "v0.0.0-20181111172936-0467c0c38ca2" this is the
+// abbreviated commit hash. For other versions this is the tag name, without
+// any "+incompatible" suffix. If the module has no version, this returns
+// an empty string.
+func (m *Module) Ref() string {
+	v := strings.TrimSuffix(m.Version, "+incompatible")
+	if ms := pseudoVersionRe.FindStringSubmatch(v); ms != nil {
+		return ms[1]
+	}
+
+	return v
 }
 
 // ParseExeData parses the raw dependency information from a compiled Go
@@ -40,6 +85,10 @@ func ParseExeData(raw string) ([]Module, error) {
 			// dependency.
 			row = append(row, "")
 		}
+		if len(row) == 2 && row[0] == "=>" {
+			// A replacement with a local directory has no version or hash.
+			row = append(row, "", "")
+		}
 
 		if len(row) != 4 {
 			return nil, fmt.Errorf(
@@ -48,19 +97,20 @@ func ParseExeData(raw string) ([]Module, error) {
 
 		// If the path ends in an import version, strip it since we have
 		// an exact version available in Version.
-		if loc := importVersionRe.FindStringIndex(row[1]); loc != nil {
-			row[1] = row[1][:loc[0]]
-		}
-
 		next := Module{
-			Path:    row[1],
+			Path:    stripImportVersion(row[1]),
 			Version: row[2],
 			Hash:    row[3],
 		}
 
-		// If this is a replacement, then replace the last result
+		// If this is a replacement, then record it on the last result
 		if row[0] == "=>" {
-			result[len(result)-1] = next
+			if len(result) == 0 {
+				return nil, fmt.Errorf(
+					"Unexpected replacement without dependency: %s", line)
+			}
+
+			result[len(result)-1].Replace = &next
 			continue
 		}
 
@@ -74,3 +124,7 @@ func ParseExeData(raw string) ([]Module, error) {
 // import version specifiers like `/v12` on an import that is Go modules
 // compatible.
 var importVersionRe = regexp.MustCompile(`/v\d+$`)
+
+// pseudoVersionRe is a regular expression that matches a pseudo-version,
+// capturing the abbreviated commit hash.
+var pseudoVersionRe = regexp.MustCompile(`^v\d+\.\d+\.\d+-(?:[0-9A-Za-z.-]+\.)?\d{14}-([0-9a-f]{12})$`)
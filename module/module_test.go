@@ -53,12 +53,36 @@ func TestParseExeData(t *testing.T) {
 			[]Module{
 				Module{
 					Path:    "github.com/markbates/inflect",
-					Version: "v0.0.0-20171215194931-a12c3aec81a6",
-					Hash:    "h1:LZhVjIISSbj8qLf2qDPP0D8z0uvOWAW5C85ly5mJW6c=",
+					Version: "v1.0.0",
+					Replace: &Module{
+						Path:    "github.com/markbates/inflect",
+						Version: "v0.0.0-20171215194931-a12c3aec81a6",
+						Hash:    "h1:LZhVjIISSbj8qLf2qDPP0D8z0uvOWAW5C85ly5mJW6c=",
+					},
 				},
 			},
 			"",
 		},
+
+		{
+			"local replacement",
+			"dep\tgopkg.in/yaml.v2\tv2.2.1\t\n=>\t../yaml\t\t",
+			[]Module{
+				Module{
+					Path:    "gopkg.in/yaml.v2",
+					Version: "v2.2.1",
+					Replace: &Module{Path: "../yaml"},
+				},
+			},
+			"",
+		},
+
+		{
+			"replacement without dependency",
+			"=>\tgithub.com/foo/bar\tv1.0.0\t",
+			nil,
+			"without dependency",
+		},
 	}
 
 	for _, tt := range cases {
@@ -76,6 +100,46 @@ func TestParseExeData(t *testing.T) {
 	}
 }
 
+func TestModuleRef(t *testing.T) {
+	cases := []struct {
+		Version string
+		Ref     string
+	}{
+		{"", ""},
+		{"v1.2.3", "v1.2.3"},
+		{"v1.2.3-rc.1", "v1.2.3-rc.1"},
+		{"v2.0.0+incompatible", "v2.0.0"},
+		{"v0.0.0-20181111172936-0467c0c38ca2", "0467c0c38ca2"},
+		{"v1.2.4-0.20181111172936-0467c0c38ca2", "0467c0c38ca2"},
+		{"v1.2.3-pre.0.20181111172936-0467c0c38ca2", "0467c0c38ca2"},
+		{"v2.0.0-20181111172936-0467c0c38ca2+incompatible", "0467c0c38ca2"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Version, func(t *testing.T) {
+			m := &Module{Path: "github.com/foo/bar", Version: tt.Version}
+			require.Equal(t, tt.Ref, m.Ref())
+		})
+	}
+}
+
+func TestModuleSource(t *testing.T) {
+	m := &Module{Path: "gopkg.in/yaml.v2", Version: "v2.2.1", Hash: "h1:a"}
+	require.Equal(t, *m, m.Source())
+	require.False(t, m.LocalReplace())
+	require.Equal(t, "gopkg.in/yaml.v2 (v2.2.1)", m.String())
+
+	m.Replace = &Module{Path: "github.com/ourorg/yaml", Version: "v2.2.2", Hash: "h1:b"}
+	require.Equal(t, Module{Path: "github.com/ourorg/yaml", Version: "v2.2.2", Hash: "h1:b"}, m.Source())
+	require.False(t, m.LocalReplace())
+	require.Equal(t, "gopkg.in/yaml.v2 (v2.2.1) => github.com/ourorg/yaml (v2.2.2)", m.String())
+
+	m.Replace = &Module{Path: "../yaml"}
+	require.Equal(t, Module{Path: "gopkg.in/yaml.v2", Version: "v2.2.1", Hash: "h1:a"}, m.Source())
+	require.True(t, m.LocalReplace())
+	require.Equal(t, "gopkg.in/yaml.v2 (v2.2.1) => ../yaml", m.String())
+}
+
 const testExeData = "path\tgithub.com/mitchellh/golicense\nmod\tgithub.com/mitchellh/golicense\t(devel)\t\ndep\tgithub.com/fatih/color\tv1.7.0\th1:DkWD4oS2D8LGGgTQ6IvwJJXSL5Vp2ffcQg58nFV38Ys=\ndep\tgithub.com/mattn/go-colorable\tv0.0.9\th1:UVL0vNpWh04HeJXV0KLcaT7r06gOH2l4OW6ddYRUIY4=\ndep\tgithub.com/mattn/go-isatty\tv0.0.4\th1:bnP0vzxcAdeI1zdubAl5PjU6zsERjGZb7raWodagDYs=\ndep\tgithub.com/rsc/goversion\tv1.2.0\th1:zVF4y5ciA/rw779S62bEAq4Yif1cBc/UwRkXJ2xZyT4=\ndep\tgithub.com/rsc/goversion/v12\tv12.0.0\th1:zVF4y5ciA/rw779S62bEAq4Yif1cBc/UwRkXJ2xZyT4=\n"
 
 const replacement = `
@@ -0,0 +1,126 @@
+package module
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CompareVersions compares two module versions such as "v1.2.3" using
+// semantic versioning precedence, returning -1 if a is lower than b, 1 if
+// a is higher than b and 0 if they are equal. Pseudo-versions are ordered
+// as prereleases and build metadata such as "+incompatible" is ignored.
+//
+// Invalid versions are lower than all valid versions and compare as
+// strings with each other.
+func CompareVersions(a, b string) int {
+	av, aok := parseVersion(a)
+	bv, bok := parseVersion(b)
+	switch {
+	case !aok && !bok:
+		return strings.Compare(a, b)
+
+	case !aok:
+		return -1
+
+	case !bok:
+		return 1
+	}
+
+	for i := 0; i < 3; i++ {
+		if c := compareInts(av.core[i], bv.core[i]); c != 0 {
+			return c
+		}
+	}
+
+	// A version without a prerelease is higher than one with a prerelease
+	switch {
+	case len(av.pre) == 0 && len(bv.pre) == 0:
+		return 0
+
+	case len(av.pre) == 0:
+		return 1
+
+	case len(bv.pre) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(av.pre) && i < len(bv.pre); i++ {
+		if c := comparePrerelease(av.pre[i], bv.pre[i]); c != 0 {
+			return c
+		}
+	}
+
+	return compareInts(len(av.pre), len(bv.pre))
+}
+
+// version is a parsed semantic version.
+type version struct {
+	core [3]int
+	pre  []string
+}
+
+// parseVersion parses a version such as "v1.2.3-pre+build". The minor and
+// patch versions may be omitted, such as "v2".
+func parseVersion(v string) (version, bool) {
+	var result version
+	if !strings.HasPrefix(v, "v") {
+		return result, false
+	}
+	v = v[1:]
+
+	if idx := strings.Index(v, "+"); idx >= 0 {
+		v = v[:idx]
+	}
+	if idx := strings.Index(v, "-"); idx >= 0 {
+		result.pre = strings.Split(v[idx+1:], ".")
+		v = v[:idx]
+	}
+
+	parts := strings.Split(v, ".")
+	if len(parts) > 3 {
+		return result, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return result, false
+		}
+
+		result.core[i] = n
+	}
+
+	return result, true
+}
+
+// comparePrerelease compares prerelease identifiers. Numeric identifiers
+// are compared numerically and are lower than alphanumeric identifiers.
+func comparePrerelease(a, b string) int {
+	an, aerr := strconv.Atoi(a)
+	bn, berr := strconv.Atoi(b)
+	switch {
+	case aerr == nil && berr == nil:
+		return compareInts(an, bn)
+
+	case aerr == nil:
+		return -1
+
+	case berr == nil:
+		return 1
+
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+
+	case a > b:
+		return 1
+
+	default:
+		return 0
+	}
+}
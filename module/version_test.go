@@ -0,0 +1,36 @@
+package module
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		A, B     string
+		Expected int
+	}{
+		{"v1.2.3", "v1.2.3", 0},
+		{"v1.2.3", "v1.2.4", -1},
+		{"v1.10.0", "v1.9.0", 1},
+		{"v2.0.0", "v1.99.99", 1},
+		{"v2", "v2.0.0", 0},
+		{"v1.0.0-rc.1", "v1.0.0", -1},
+		{"v1.0.0-alpha", "v1.0.0-beta", -1},
+		{"v1.0.0-alpha.2", "v1.0.0-alpha.10", -1},
+		{"v1.0.0-alpha", "v1.0.0-alpha.1", -1},
+		{"v2.0.0+incompatible", "v2.0.0", 0},
+		{"v0.0.0-20181111172936-0467c0c38ca2", "v0.0.0-20190101000000-abcdefabcdef", -1},
+		{"v0.0.0-20181111172936-0467c0c38ca2", "v0.1.0", -1},
+		{"", "v0.0.1", -1},
+		{"foo", "bar", 1},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.A+"/"+tt.B, func(t *testing.T) {
+			require.Equal(t, tt.Expected, CompareVersions(tt.A, tt.B))
+			require.Equal(t, -tt.Expected, CompareVersions(tt.B, tt.A))
+		})
+	}
+}
@@ -24,6 +24,24 @@ type Output interface {
 	Close() error
 }
 
+// moduleKey returns the key of a module in maps of modules such as
+// ModuleBinaries. Modules with the same version but different hashes have
+// different keys since their source differs.
+func moduleKey(m *module.Module) string {
+	return m.String() + " " + m.Hash
+}
+
+// moduleBinaries returns the paths of the binaries that a module was
+// found in. index is the binaries keyed by moduleKey. If index is nil,
+// the module is assumed to be in all binaries.
+func moduleBinaries(m *module.Module, index map[string][]string, all []string) []string {
+	if index == nil {
+		return all
+	}
+
+	return index[moduleKey(m)]
+}
+
 // StatusListener returns a license.StatusListener implementation for
 // a single module to route to an Output implementation.
 //
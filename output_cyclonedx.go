@@ -0,0 +1,354 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/license/expr"
+	"github.com/mitchellh/golicense/module"
+)
+
+// CycloneDXOutput writes the results of license lookups as a CycloneDX 1.4
+// bill of materials. Every module is a library component identified by
+// its package URL.
+type CycloneDXOutput struct {
+	// Path is the path to the file to write. This will be overwritten if
+	// it exists.
+	Path string
+
+	// Format is the serialization format, either "json" or "xml". If this
+	// is empty, the format is determined by the extension of Path: ".xml"
+	// for XML, JSON otherwise.
+	Format string
+
+	// Binaries is the list of paths to the binaries that were analyzed.
+	Binaries []string
+
+	// ModuleBinaries are the paths of the binaries that each module was
+	// found in, keyed by moduleKey. If this is nil, every module is
+	// assumed to be in every binary.
+	ModuleBinaries map[string][]string
+
+	modules map[*module.Module]lookupResult
+	lock    sync.Mutex
+}
+
+// Start implements Output
+func (o *CycloneDXOutput) Start(m *module.Module) {}
+
+// Update implements Output
+func (o *CycloneDXOutput) Update(m *module.Module, t license.StatusType, msg string) {}
+
+// Finish implements Output
+func (o *CycloneDXOutput) Finish(m *module.Module, l *license.License, err error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if o.modules == nil {
+		o.modules = make(map[*module.Module]lookupResult)
+	}
+
+	o.modules[m] = lookupResult{License: l, Err: err}
+}
+
+// Close implements Output
+func (o *CycloneDXOutput) Close() error {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	bom, err := o.bom()
+	if err != nil {
+		return err
+	}
+
+	format := o.Format
+	if format == "" {
+		format = "json"
+		if filepath.Ext(o.Path) == ".xml" {
+			format = "xml"
+		}
+	}
+
+	f, err := os.Create(o.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	switch format {
+	case "json":
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(bom)
+
+	case "xml":
+		if _, err = f.WriteString(xml.Header); err != nil {
+			return err
+		}
+
+		enc := xml.NewEncoder(f)
+		enc.Indent("", "  ")
+		if err = enc.Encode(bom); err == nil {
+			_, err = f.WriteString("\n")
+		}
+
+	default:
+		err = fmt.Errorf("unknown CycloneDX format %q, must be 'json' or 'xml'", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+// bom builds the CycloneDX BOM from the recorded results.
+//
+// lock must be held.
+func (o *CycloneDXOutput) bom() (*cdxBOM, error) {
+	uuid, err := newUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	bom := &cdxBOM{
+		XMLNS:        "http://cyclonedx.org/schema/bom/1.4",
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.4",
+		SerialNumber: "urn:uuid:" + uuid,
+		Version:      1,
+		Metadata: &cdxMetadata{
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Tools:     []*cdxTool{{Name: "golicense"}},
+		},
+
+		// The schema requires an array even if there are no components
+		Components: []*cdxComponent{},
+	}
+
+	// Every binary is an application. If there is only one binary then
+	// the BOM describes it directly, otherwise they're all components.
+	for _, bin := range o.Binaries {
+		c := &cdxComponent{
+			Type:   "application",
+			BOMRef: "binary:" + bin,
+			Name:   filepath.Base(bin),
+		}
+		if len(o.Binaries) == 1 {
+			bom.Metadata.Component = c
+			continue
+		}
+
+		bom.Components = append(bom.Components, c)
+	}
+
+	// Sort the modules so that the document is stable
+	mods := make([]*module.Module, 0, len(o.modules))
+	for m := range o.modules {
+		mods = append(mods, m)
+	}
+	sort.Slice(mods, func(i, j int) bool {
+		if mods[i].Path != mods[j].Path {
+			return mods[i].Path < mods[j].Path
+		}
+
+		return mods[i].Version < mods[j].Version
+	})
+
+	deps := make(map[string][]string)
+	refs := make(map[string]struct{})
+	for _, m := range mods {
+		// Components describe the source that was built, which is the
+		// replacement for modules replaced by another module.
+		r, src := o.modules[m], m.Source()
+		purl := packageURL(&src)
+		c := &cdxComponent{
+			Type:    "library",
+			BOMRef:  cdxRef(refs, purl),
+			Name:    src.FullPath(),
+			Version: src.Version,
+			PURL:    purl,
+		}
+		if l := r.License; l != nil {
+			if id, ok := cdxLicenseID(l.SPDX); ok {
+				lic := &cdxLicense{ID: id}
+				c.Licenses = []*cdxLicenseChoice{{License: lic}}
+				c.XMLLicenses = &cdxXMLLicenses{Licenses: []*cdxLicense{lic}}
+			} else if l.SPDX != "" && l.SPDX != "NOASSERTION" {
+				c.Licenses = []*cdxLicenseChoice{{Expression: l.SPDX}}
+				c.XMLLicenses = &cdxXMLLicenses{Expression: l.SPDX}
+			} else {
+				lic := &cdxLicense{Name: l.Name}
+				c.Licenses = []*cdxLicenseChoice{{License: lic}}
+				c.XMLLicenses = &cdxXMLLicenses{Licenses: []*cdxLicense{lic}}
+			}
+		}
+
+		// The h1 hash is a hash of the module's file tree as recorded in
+		// go.sum, not of the component, so it isn't one of the hashes.
+		if src.Hash != "" {
+			c.Properties = append(c.Properties, &cdxProperty{
+				Name:  "golicense:h1",
+				Value: src.Hash,
+			})
+		}
+		if m.LocalReplace() {
+			c.Properties = append(c.Properties, &cdxProperty{
+				Name:  "golicense:replaced-by",
+				Value: m.Replace.Path,
+			})
+		} else if m.Replace != nil {
+			c.Properties = append(c.Properties, &cdxProperty{
+				Name:  "golicense:replaces",
+				Value: m.Path + "@" + m.Version,
+			})
+		}
+		if r.Err != nil {
+			c.Properties = append(c.Properties, &cdxProperty{
+				Name:  "golicense:error",
+				Value: r.Err.Error(),
+			})
+		}
+		if len(c.Properties) > 0 {
+			c.XMLProps = &cdxXMLProperties{Properties: c.Properties}
+		}
+
+		for _, bin := range moduleBinaries(m, o.ModuleBinaries, o.Binaries) {
+			deps[bin] = append(deps[bin], c.BOMRef)
+		}
+		bom.Components = append(bom.Components, c)
+	}
+
+	for _, bin := range o.Binaries {
+		modRefs := deps[bin]
+		xmlDeps := make([]*cdxDependency, len(modRefs))
+		for i, ref := range modRefs {
+			xmlDeps[i] = &cdxDependency{Ref: ref}
+		}
+
+		bom.Dependencies = append(bom.Dependencies, &cdxDependency{
+			Ref:       "binary:" + bin,
+			DependsOn: modRefs,
+			XMLDeps:   xmlDeps,
+		})
+	}
+
+	return bom, nil
+}
+
+// cdxRef returns a unique bom-ref based on the package URL of a
+// component. Several modules can have the same source, such as two
+// modules replaced by the same module, so later components get a "#2",
+// "#3" and so on suffix. refs is the set of refs already in use and is
+// updated with the result.
+func cdxRef(refs map[string]struct{}, purl string) string {
+	result := purl
+	for i := 2; ; i++ {
+		if _, ok := refs[result]; !ok {
+			break
+		}
+
+		result = fmt.Sprintf("%s#%d", purl, i)
+	}
+
+	refs[result] = struct{}{}
+	return result
+}
+
+// cdxLicenseID returns the license ID for an SPDX ID. CycloneDX only
+// allows a single license ID as the ID of a license, so this returns false
+// for anything else, including an ID with the "+" operator or an
+// exception, which must be written as an expression instead.
+func cdxLicenseID(v string) (string, bool) {
+	if v == "" || v == "NOASSERTION" {
+		return "", false
+	}
+
+	e, err := expr.Parse(v)
+	if err != nil {
+		return "", false
+	}
+
+	l, ok := e.(*expr.License)
+	if !ok || l.OrLater || l.Exception != "" {
+		return "", false
+	}
+
+	return l.ID, true
+}
+
+// cdxBOM is a CycloneDX 1.4 BOM. The types below are shared between the
+// JSON and XML formats. Where the two formats differ in structure, the
+// type has separate fields for each format.
+type cdxBOM struct {
+	XMLName      xml.Name         `json:"-" xml:"bom"`
+	XMLNS        string           `json:"-" xml:"xmlns,attr"`
+	BOMFormat    string           `json:"bomFormat" xml:"-"`
+	SpecVersion  string           `json:"specVersion" xml:"-"`
+	SerialNumber string           `json:"serialNumber" xml:"serialNumber,attr"`
+	Version      int              `json:"version" xml:"version,attr"`
+	Metadata     *cdxMetadata     `json:"metadata" xml:"metadata"`
+	Components   []*cdxComponent  `json:"components" xml:"components>component"`
+	Dependencies []*cdxDependency `json:"dependencies,omitempty" xml:"dependencies>dependency,omitempty"`
+}
+
+type cdxMetadata struct {
+	Timestamp string        `json:"timestamp" xml:"timestamp"`
+	Tools     []*cdxTool    `json:"tools" xml:"tools>tool"`
+	Component *cdxComponent `json:"component,omitempty" xml:"component,omitempty"`
+}
+
+type cdxTool struct {
+	Name string `json:"name" xml:"name"`
+}
+
+type cdxComponent struct {
+	Type        string              `json:"type" xml:"type,attr"`
+	BOMRef      string              `json:"bom-ref" xml:"bom-ref,attr"`
+	Name        string              `json:"name" xml:"name"`
+	Version     string              `json:"version,omitempty" xml:"version,omitempty"`
+	Licenses    []*cdxLicenseChoice `json:"licenses,omitempty" xml:"-"`
+	XMLLicenses *cdxXMLLicenses     `json:"-" xml:"licenses,omitempty"`
+	PURL        string              `json:"purl,omitempty" xml:"purl,omitempty"`
+	Properties  []*cdxProperty      `json:"properties,omitempty" xml:"-"`
+	XMLProps    *cdxXMLProperties   `json:"-" xml:"properties,omitempty"`
+}
+
+// The XML wrapper types below exist so that the wrapping element is
+// omitted entirely when there are no values.
+type cdxXMLLicenses struct {
+	Licenses   []*cdxLicense `xml:"license"`
+	Expression string        `xml:"expression,omitempty"`
+}
+
+type cdxXMLProperties struct {
+	Properties []*cdxProperty `xml:"property"`
+}
+
+type cdxLicenseChoice struct {
+	License    *cdxLicense `json:"license,omitempty"`
+	Expression string      `json:"expression,omitempty"`
+}
+
+type cdxLicense struct {
+	ID   string `json:"id,omitempty" xml:"id,omitempty"`
+	Name string `json:"name,omitempty" xml:"name,omitempty"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name" xml:"name,attr"`
+	Value string `json:"value" xml:",chardata"`
+}
+
+type cdxDependency struct {
+	Ref       string           `json:"ref" xml:"ref,attr"`
+	DependsOn []string         `json:"dependsOn,omitempty" xml:"-"`
+	XMLDeps   []*cdxDependency `json:"-" xml:"dependency,omitempty"`
+}
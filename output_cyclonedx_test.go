@@ -0,0 +1,335 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCycloneDXOutput(t *testing.T) {
+	github := &module.Module{Path: "github.com/google/go-github", Version: "v18.2.0", Hash: "h1:gh"}
+	failed := &module.Module{Path: "github.com/example/failed", Version: "v0.1.0"}
+	local := &module.Module{
+		Path:    "github.com/example/local",
+		Version: "v1.0.0",
+		Replace: &module.Module{Path: "../local"},
+	}
+
+	// Two modules replaced by the same module have the same package URL
+	replaceA := &module.Module{
+		Path:    "github.com/example/a",
+		Version: "v1.0.0",
+		Replace: &module.Module{Path: "github.com/fork/x", Version: "v1.1.0", Hash: "h1:x"},
+	}
+	replaceB := &module.Module{
+		Path:    "github.com/example/b",
+		Version: "v1.0.0",
+		Replace: &module.Module{Path: "github.com/fork/x", Version: "v1.1.0", Hash: "h1:x"},
+	}
+
+	o := &CycloneDXOutput{
+		Path:     filepath.Join(t.TempDir(), "bom.json"),
+		Binaries: []string{"/a/app", "/b/app"},
+		ModuleBinaries: map[string][]string{
+			moduleKey(github):   {"/a/app", "/b/app"},
+			moduleKey(failed):   {"/a/app"},
+			moduleKey(local):    {"/b/app"},
+			moduleKey(replaceA): {"/a/app"},
+			moduleKey(replaceB): {"/b/app"},
+		},
+	}
+	o.Finish(github, &license.License{Name: "BSD 3-Clause", SPDX: "BSD-3-Clause"}, nil)
+	o.Finish(failed, nil, errors.New("rate limited"))
+	o.Finish(local, &license.License{Name: "Custom License"}, nil)
+	o.Finish(replaceA, &license.License{Name: "GPL", SPDX: "GPL-2.0-or-later WITH Classpath-exception-2.0"}, nil)
+	o.Finish(replaceB, &license.License{Name: "MIT OR Apache", SPDX: "MIT OR Apache-2.0"}, nil)
+
+	bom, err := o.bom()
+	require.NoError(t, err)
+	require.Equal(t, "CycloneDX", bom.BOMFormat)
+	require.Equal(t, "1.4", bom.SpecVersion)
+	require.True(t, strings.HasPrefix(bom.SerialNumber, "urn:uuid:"))
+	require.Nil(t, bom.Metadata.Component)
+
+	type component struct {
+		Type, Ref, Name, Version, PURL string
+		Licenses                       []*cdxLicenseChoice
+		Properties                     []*cdxProperty
+	}
+	var actual []component
+	for _, c := range bom.Components {
+		actual = append(actual, component{
+			Type:       c.Type,
+			Ref:        c.BOMRef,
+			Name:       c.Name,
+			Version:    c.Version,
+			PURL:       c.PURL,
+			Licenses:   c.Licenses,
+			Properties: c.Properties,
+		})
+	}
+	require.Equal(t, []component{
+		{Type: "application", Ref: "binary:/a/app", Name: "app"},
+		{Type: "application", Ref: "binary:/b/app", Name: "app"},
+		{
+			Type:     "library",
+			Ref:      "pkg:golang/github.com/fork/x@v1.1.0",
+			Name:     "github.com/fork/x",
+			Version:  "v1.1.0",
+			PURL:     "pkg:golang/github.com/fork/x@v1.1.0",
+			Licenses: []*cdxLicenseChoice{{Expression: "GPL-2.0-or-later WITH Classpath-exception-2.0"}},
+			Properties: []*cdxProperty{
+				{Name: "golicense:h1", Value: "h1:x"},
+				{Name: "golicense:replaces", Value: "github.com/example/a@v1.0.0"},
+			},
+		},
+		{
+			Type:     "library",
+			Ref:      "pkg:golang/github.com/fork/x@v1.1.0#2",
+			Name:     "github.com/fork/x",
+			Version:  "v1.1.0",
+			PURL:     "pkg:golang/github.com/fork/x@v1.1.0",
+			Licenses: []*cdxLicenseChoice{{Expression: "MIT OR Apache-2.0"}},
+			Properties: []*cdxProperty{
+				{Name: "golicense:h1", Value: "h1:x"},
+				{Name: "golicense:replaces", Value: "github.com/example/b@v1.0.0"},
+			},
+		},
+		{
+			Type:    "library",
+			Ref:     "pkg:golang/github.com/example/failed@v0.1.0",
+			Name:    "github.com/example/failed",
+			Version: "v0.1.0",
+			PURL:    "pkg:golang/github.com/example/failed@v0.1.0",
+			Properties: []*cdxProperty{
+				{Name: "golicense:error", Value: "rate limited"},
+			},
+		},
+		{
+			Type:     "library",
+			Ref:      "pkg:golang/github.com/example/local@v1.0.0",
+			Name:     "github.com/example/local",
+			Version:  "v1.0.0",
+			PURL:     "pkg:golang/github.com/example/local@v1.0.0",
+			Licenses: []*cdxLicenseChoice{{License: &cdxLicense{Name: "Custom License"}}},
+			Properties: []*cdxProperty{
+				{Name: "golicense:replaced-by", Value: "../local"},
+			},
+		},
+		{
+			Type:     "library",
+			Ref:      "pkg:golang/github.com/google/go-github/v18@v18.2.0",
+			Name:     "github.com/google/go-github/v18",
+			Version:  "v18.2.0",
+			PURL:     "pkg:golang/github.com/google/go-github/v18@v18.2.0",
+			Licenses: []*cdxLicenseChoice{{License: &cdxLicense{ID: "BSD-3-Clause"}}},
+			Properties: []*cdxProperty{
+				{Name: "golicense:h1", Value: "h1:gh"},
+			},
+		},
+	}, actual)
+
+	deps := make(map[string][]string)
+	for _, d := range bom.Dependencies {
+		deps[d.Ref] = d.DependsOn
+	}
+	require.Equal(t, map[string][]string{
+		"binary:/a/app": {
+			"pkg:golang/github.com/fork/x@v1.1.0",
+			"pkg:golang/github.com/example/failed@v0.1.0",
+			"pkg:golang/github.com/google/go-github/v18@v18.2.0",
+		},
+		"binary:/b/app": {
+			"pkg:golang/github.com/fork/x@v1.1.0#2",
+			"pkg:golang/github.com/example/local@v1.0.0",
+			"pkg:golang/github.com/google/go-github/v18@v18.2.0",
+		},
+	}, deps)
+}
+
+func TestCycloneDXOutput_json(t *testing.T) {
+	cases := []struct {
+		Name       string
+		Modules    []*module.Module
+		Components string
+	}{
+		{
+			"no modules",
+			nil,
+			`[]`,
+		},
+
+		{
+			"module",
+			[]*module.Module{{Path: "github.com/example/a", Version: "v1.0.0", Hash: "h1:a"}},
+			`[{
+				"type": "library",
+				"bom-ref": "pkg:golang/github.com/example/a@v1.0.0",
+				"name": "github.com/example/a",
+				"version": "v1.0.0",
+				"licenses": [{"license": {"id": "MIT"}}],
+				"purl": "pkg:golang/github.com/example/a@v1.0.0",
+				"properties": [{"name": "golicense:h1", "value": "h1:a"}]
+			}]`,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			o := &CycloneDXOutput{
+				Path:     filepath.Join(t.TempDir(), "bom.json"),
+				Binaries: []string{"/bin/app"},
+			}
+			for _, m := range tt.Modules {
+				o.Finish(m, &license.License{Name: "MIT License", SPDX: "MIT"}, nil)
+			}
+			require.NoError(t, o.Close())
+
+			data, err := ioutil.ReadFile(o.Path)
+			require.NoError(t, err)
+
+			var bom struct {
+				BOMFormat   string          `json:"bomFormat"`
+				SpecVersion string          `json:"specVersion"`
+				Components  json.RawMessage `json:"components"`
+				Metadata    struct {
+					Component struct {
+						Type   string `json:"type"`
+						BOMRef string `json:"bom-ref"`
+						Name   string `json:"name"`
+					} `json:"component"`
+				} `json:"metadata"`
+			}
+			require.NoError(t, json.Unmarshal(data, &bom))
+			require.Equal(t, "CycloneDX", bom.BOMFormat)
+			require.Equal(t, "1.4", bom.SpecVersion)
+			require.Equal(t, "application", bom.Metadata.Component.Type)
+			require.Equal(t, "binary:/bin/app", bom.Metadata.Component.BOMRef)
+			require.Equal(t, "app", bom.Metadata.Component.Name)
+			require.JSONEq(t, tt.Components, string(bom.Components))
+		})
+	}
+}
+
+func TestCycloneDXOutput_xml(t *testing.T) {
+	o := &CycloneDXOutput{
+		Path:     filepath.Join(t.TempDir(), "bom.xml"),
+		Binaries: []string{"/bin/app"},
+	}
+	o.Finish(
+		&module.Module{Path: "github.com/example/a", Version: "v1.0.0", Hash: "h1:a"},
+		&license.License{Name: "MIT License", SPDX: "MIT"}, nil)
+	o.Finish(
+		&module.Module{Path: "github.com/example/b", Version: "v1.0.0"},
+		&license.License{Name: "MIT OR Apache", SPDX: "MIT OR Apache-2.0"}, nil)
+	require.NoError(t, o.Close())
+
+	data, err := ioutil.ReadFile(o.Path)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(data), xml.Header))
+
+	var bom struct {
+		XMLName    xml.Name
+		Version    int    `xml:"version,attr"`
+		Serial     string `xml:"serialNumber,attr"`
+		Components []struct {
+			Type       string   `xml:"type,attr"`
+			BOMRef     string   `xml:"bom-ref,attr"`
+			Name       string   `xml:"name"`
+			PURL       string   `xml:"purl"`
+			LicenseIDs []string `xml:"licenses>license>id"`
+			Expression string   `xml:"licenses>expression"`
+			Properties []struct {
+				Name  string `xml:"name,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"properties>property"`
+		} `xml:"components>component"`
+		Dependencies []struct {
+			Ref       string `xml:"ref,attr"`
+			DependsOn []struct {
+				Ref string `xml:"ref,attr"`
+			} `xml:"dependency"`
+		} `xml:"dependencies>dependency"`
+	}
+	require.NoError(t, xml.Unmarshal(data, &bom))
+	require.Equal(t, "http://cyclonedx.org/schema/bom/1.4", bom.XMLName.Space)
+	require.Equal(t, "bom", bom.XMLName.Local)
+	require.Equal(t, 1, bom.Version)
+	require.True(t, strings.HasPrefix(bom.Serial, "urn:uuid:"))
+
+	require.Len(t, bom.Components, 2)
+	a, b := bom.Components[0], bom.Components[1]
+	require.Equal(t, "library", a.Type)
+	require.Equal(t, "pkg:golang/github.com/example/a@v1.0.0", a.BOMRef)
+	require.Equal(t, "github.com/example/a", a.Name)
+	require.Equal(t, "pkg:golang/github.com/example/a@v1.0.0", a.PURL)
+	require.Equal(t, []string{"MIT"}, a.LicenseIDs)
+	require.Empty(t, a.Expression)
+	require.Len(t, a.Properties, 1)
+	require.Equal(t, "golicense:h1", a.Properties[0].Name)
+	require.Equal(t, "h1:a", a.Properties[0].Value)
+	require.Empty(t, b.LicenseIDs)
+	require.Equal(t, "MIT OR Apache-2.0", b.Expression)
+	require.Empty(t, b.Properties)
+
+	require.Len(t, bom.Dependencies, 1)
+	require.Equal(t, "binary:/bin/app", bom.Dependencies[0].Ref)
+	require.Len(t, bom.Dependencies[0].DependsOn, 2)
+	require.Equal(t, a.BOMRef, bom.Dependencies[0].DependsOn[0].Ref)
+	require.Equal(t, b.BOMRef, bom.Dependencies[0].DependsOn[1].Ref)
+}
+
+func TestCdxLicenseID(t *testing.T) {
+	cases := []struct {
+		Input    string
+		Expected string
+		OK       bool
+	}{
+		{"MIT", "MIT", true},
+		{"Apache-2.0", "Apache-2.0", true},
+		{"LicenseRef-Custom", "LicenseRef-Custom", true},
+		{"", "", false},
+		{"NOASSERTION", "", false},
+		{"GPL-2.0+", "", false},
+		{"GPL-2.0-or-later WITH Classpath-exception-2.0", "", false},
+		{"MIT OR Apache-2.0", "", false},
+		{"MIT AND BSD-3-Clause", "", false},
+		{"(MIT", "", false},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Input, func(t *testing.T) {
+			actual, ok := cdxLicenseID(tt.Input)
+			require.Equal(t, tt.OK, ok)
+			require.Equal(t, tt.Expected, actual)
+		})
+	}
+}
+
+func TestCdxRef(t *testing.T) {
+	refs := make(map[string]struct{})
+	var actual []string
+	for _, purl := range []string{
+		"pkg:golang/a@v1.0.0",
+		"pkg:golang/b@v1.0.0",
+		"pkg:golang/a@v1.0.0",
+		"pkg:golang/a@v1.0.0",
+	} {
+		actual = append(actual, cdxRef(refs, purl))
+	}
+
+	require.Equal(t, []string{
+		"pkg:golang/a@v1.0.0",
+		"pkg:golang/b@v1.0.0",
+		"pkg:golang/a@v1.0.0#2",
+		"pkg:golang/a@v1.0.0#3",
+	}, actual)
+}
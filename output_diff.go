@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+)
+
+// DiffOutput is an Output implementation that writes the modules that
+// changed since a baseline, along with any license changes, to the
+// terminal on Close.
+type DiffOutput struct {
+	// Out is where to write the changes.
+	Out io.Writer
+
+	// Baseline is the name of the baseline, such as the path to the
+	// binary or JSON report, shown in the output.
+	Baseline string
+
+	// Changes are the module changes since the baseline. The licenses of
+	// the new modules are recorded on Finish. The licenses of the old
+	// modules are set with SetBaseline.
+	Changes []*module.Change
+
+	old  map[string]lookupResult
+	new  map[string]lookupResult
+	lock sync.Mutex
+}
+
+// SetBaseline sets the result of the license lookup of a module in the
+// baseline.
+func (o *DiffOutput) SetBaseline(m *module.Module, l *license.License, err error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if o.old == nil {
+		o.old = make(map[string]lookupResult)
+	}
+
+	o.old[moduleKey(m)] = lookupResult{License: l, Err: err}
+}
+
+// Start implements Output
+func (o *DiffOutput) Start(m *module.Module) {}
+
+// Update implements Output
+func (o *DiffOutput) Update(m *module.Module, t license.StatusType, msg string) {}
+
+// Finish implements Output
+func (o *DiffOutput) Finish(m *module.Module, l *license.License, err error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if o.new == nil {
+		o.new = make(map[string]lookupResult)
+	}
+
+	o.new[moduleKey(m)] = lookupResult{License: l, Err: err}
+}
+
+// Close implements Output
+func (o *DiffOutput) Close() error {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	fmt.Fprintf(o.Out, "\nChanges since %s:\n\n", o.Baseline)
+	if len(o.Changes) == 0 {
+		fmt.Fprintf(o.Out, "  no module changes\n")
+		return nil
+	}
+
+	var added, removed, changed, relicensed int
+	for _, c := range o.Changes {
+		var old, new lookupResult
+		var hasOld bool
+		if c.Old != nil {
+			old, hasOld = o.old[moduleKey(c.Old)]
+		}
+		if c.New != nil {
+			new = o.new[moduleKey(c.New)]
+		}
+
+		switch {
+		case c.Added():
+			added++
+			fmt.Fprintln(o.Out, color.GreenString("  + %s %s  %s",
+				c.Path, diffVersion(c.New), diffLicense(new)))
+
+		case c.Removed():
+			removed++
+			text := fmt.Sprintf("  - %s %s", c.Path, diffVersion(c.Old))
+			if hasOld {
+				text += "  " + diffLicense(old)
+			}
+
+			fmt.Fprintln(o.Out, color.RedString("%s", text))
+
+		default:
+			changed++
+			text := fmt.Sprintf("  ~ %s %s => %s  ", c.Path, diffVersion(c.Old), diffVersion(c.New))
+			if hasOld && !sameDiffLicense(old, new) {
+				relicensed++
+				fmt.Fprintln(o.Out, color.YellowString("%s%s => %s (license changed)",
+					text, diffLicense(old), diffLicense(new)))
+				continue
+			}
+
+			fmt.Fprintln(o.Out, text+diffLicense(new))
+		}
+	}
+
+	fmt.Fprintf(o.Out, "\n%d added, %d removed, %d changed version, %d changed license\n",
+		added, removed, changed, relicensed)
+	return nil
+}
+
+// diffVersion returns the version of a module for the diff output,
+// including the replacement if the module was replaced.
+func diffVersion(m *module.Module) string {
+	if m.Replace == nil {
+		return m.Version
+	}
+
+	return fmt.Sprintf("%s (%s)", m.Version, replaceNote(m))
+}
+
+// diffLicense returns the license of a lookup for the diff output.
+func diffLicense(r lookupResult) string {
+	if r.License == nil && r.Err != nil {
+		return fmt.Sprintf("ERROR: %s", r.Err)
+	}
+
+	return r.License.String()
+}
+
+// sameDiffLicense returns true if two lookups found the same license.
+// Licenses are compared by SPDX ID, or by name if either has no ID. If
+// either lookup failed, the license is considered unchanged since it
+// can't be compared.
+func sameDiffLicense(old, new lookupResult) bool {
+	if (old.License == nil && old.Err != nil) || (new.License == nil && new.Err != nil) {
+		return true
+	}
+	if old.License == nil || new.License == nil {
+		return old.License == new.License
+	}
+	if old.License.SPDX != "" && new.License.SPDX != "" {
+		return strings.EqualFold(old.License.SPDX, new.License.SPDX)
+	}
+
+	return strings.EqualFold(old.License.Name, new.License.Name)
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/golicense/config"
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+)
+
+// JSONOutput writes the results of license lookups to a JSON file.
+type JSONOutput struct {
+	// Path is the path to the file to write. This will be overwritten if
+	// it exists.
+	Path string
+
+	// Config is the configuration (if any). This will be used to check
+	// if a license is allowed or not.
+	Config *config.Config
+
+	// Builds is the build information of the binaries that were analyzed
+	// by path, if available.
+	Builds map[string]*module.BuildInfo
+
+	// ModuleBinaries are the paths of the binaries or other inputs that
+	// each module was found in, keyed by moduleKey.
+	ModuleBinaries map[string][]string
+
+	// Groups are the modules of all binaries grouped by path.
+	Groups []*module.Group
+
+	modules map[*module.Module]lookupResult
+	lock    sync.Mutex
+}
+
+// lookupResult is the result of a single module license lookup, stored
+// by outputs that write their report on Close.
+type lookupResult struct {
+	License *license.License
+	Err     error
+}
+
+// jsonReport is the top-level structure of the JSON report.
+type jsonReport struct {
+	Binaries []*jsonBinary `json:"binaries,omitempty"`
+	Modules  []*jsonModule `json:"modules"`
+	Groups   []*jsonGroup  `json:"groups,omitempty"`
+}
+
+// jsonGroup is the versions of a module path across binaries.
+type jsonGroup struct {
+	Path         string         `json:"path"`
+	Versions     []*jsonVersion `json:"versions"`
+	Skew         bool           `json:"skew"`
+	HashMismatch bool           `json:"hash_mismatch,omitempty"`
+}
+
+// jsonVersion is a single version of a module path and the binaries it
+// was found in.
+type jsonVersion struct {
+	Version  string       `json:"version"`
+	Hash     string       `json:"hash,omitempty"`
+	Replace  *jsonReplace `json:"replace,omitempty"`
+	Binaries []string     `json:"binaries,omitempty"`
+}
+
+// jsonBinary is the build information of a binary within the JSON report.
+type jsonBinary struct {
+	Path       string            `json:"path"`
+	GoVersion  string            `json:"go_version"`
+	Package    string            `json:"package"`
+	MainModule *jsonMainModule   `json:"main_module"`
+	Settings   map[string]string `json:"settings,omitempty"`
+}
+
+// jsonMainModule is the main module of a binary.
+type jsonMainModule struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+}
+
+// jsonModule is a single module within the JSON report.
+type jsonModule struct {
+	Path      string         `json:"path"`
+	Version   string         `json:"version"`
+	Hash      string         `json:"hash,omitempty"`
+	Replace   *jsonReplace   `json:"replace,omitempty"`
+	Binaries  []string       `json:"binaries,omitempty"`
+	License   *jsonLicense   `json:"license"`
+	Allowed   string         `json:"allowed"`
+	Warned    bool           `json:"warned,omitempty"`
+	Exception *jsonException `json:"exception,omitempty"`
+	Error     string         `json:"error,omitempty"`
+}
+
+// jsonReplace is the replacement of a module with a replace directive. A
+// replacement with a local directory has no version.
+type jsonReplace struct {
+	Path    string `json:"path"`
+	Version string `json:"version,omitempty"`
+	Hash    string `json:"hash,omitempty"`
+}
+
+// jsonException is the policy exception that applies to a module.
+type jsonException struct {
+	License  string `json:"license,omitempty"`
+	Reason   string `json:"reason"`
+	Expires  string `json:"expires,omitempty"`
+	Versions string `json:"versions,omitempty"`
+	Expired  bool   `json:"expired"`
+}
+
+// jsonLicense is the license of a module within the JSON report.
+type jsonLicense struct {
+	Name         string             `json:"name"`
+	SPDX         string             `json:"spdx,omitempty"`
+	Warnings     []string           `json:"warnings,omitempty"`
+	Files        []*jsonLicenseFile `json:"files,omitempty"`
+	Source       string             `json:"source,omitempty"`
+	Confidence   float32            `json:"confidence,omitempty"`
+	Translations []string           `json:"translations,omitempty"`
+	Candidates   []*jsonCandidate   `json:"candidates,omitempty"`
+}
+
+// jsonCandidate is a license that closely matched the license text of a
+// module that couldn't be detected with confidence.
+type jsonCandidate struct {
+	SPDX       string  `json:"spdx"`
+	Confidence float32 `json:"confidence"`
+}
+
+// jsonLicenseFile is a license detected in a single license file.
+type jsonLicenseFile struct {
+	Path       string  `json:"path"`
+	Name       string  `json:"name"`
+	SPDX       string  `json:"spdx,omitempty"`
+	Confidence float32 `json:"confidence,omitempty"`
+}
+
+// Start implements Output
+func (o *JSONOutput) Start(m *module.Module) {}
+
+// Update implements Output
+func (o *JSONOutput) Update(m *module.Module, t license.StatusType, msg string) {}
+
+// Finish implements Output
+func (o *JSONOutput) Finish(m *module.Module, l *license.License, err error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if o.modules == nil {
+		o.modules = make(map[*module.Module]lookupResult)
+	}
+
+	o.modules[m] = lookupResult{License: l, Err: err}
+}
+
+// Close implements Output
+func (o *JSONOutput) Close() error {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	report := jsonReport{Modules: make([]*jsonModule, 0, len(o.modules))}
+	for m, r := range o.modules {
+		jm := &jsonModule{
+			Path:     m.Path,
+			Version:  m.Version,
+			Hash:     m.Hash,
+			Binaries: o.ModuleBinaries[moduleKey(m)],
+			Allowed:  config.StateUnknown.String(),
+		}
+		if r := m.Replace; r != nil {
+			jm.Replace = &jsonReplace{Path: r.Path, Version: r.Version, Hash: r.Hash}
+		}
+		if r.License != nil {
+			jm.License = &jsonLicense{
+				Name:         r.License.Name,
+				SPDX:         r.License.SPDX,
+				Warnings:     r.License.Warnings,
+				Source:       r.License.Source,
+				Confidence:   r.License.Confidence,
+				Translations: r.License.Translations,
+			}
+			for _, f := range r.License.Files {
+				jm.License.Files = append(jm.License.Files, &jsonLicenseFile{
+					Path:       f.Path,
+					Name:       f.Name,
+					SPDX:       f.SPDX,
+					Confidence: f.Confidence,
+				})
+			}
+			for _, c := range r.License.Candidates {
+				jm.License.Candidates = append(jm.License.Candidates, &jsonCandidate{
+					SPDX:       c.SPDX,
+					Confidence: c.Confidence,
+				})
+			}
+		}
+		if o.Config != nil {
+			state, e := o.Config.ModuleAllowed(m, r.License)
+			jm.Allowed = state.String()
+			result, _ := checkModule(o.Config, m, r.License, r.Err)
+			jm.Warned = result == outcomeWarned
+			if e != nil {
+				jm.Exception = &jsonException{
+					License:  e.License,
+					Reason:   e.Reason,
+					Expires:  e.Expires,
+					Versions: e.Versions,
+					Expired:  e.Expired(time.Now()),
+				}
+			}
+		}
+		if r.Err != nil {
+			jm.Error = r.Err.Error()
+		}
+
+		report.Modules = append(report.Modules, jm)
+	}
+
+	for _, g := range o.Groups {
+		jg := &jsonGroup{Path: g.Path, Skew: g.Skew(), HashMismatch: g.HashMismatch()}
+		for i := range g.Modules {
+			m := &g.Modules[i]
+			jv := &jsonVersion{
+				Version:  m.Version,
+				Hash:     m.Hash,
+				Binaries: o.ModuleBinaries[moduleKey(m)],
+			}
+			if r := m.Replace; r != nil {
+				jv.Replace = &jsonReplace{Path: r.Path, Version: r.Version, Hash: r.Hash}
+			}
+
+			jg.Versions = append(jg.Versions, jv)
+		}
+
+		report.Groups = append(report.Groups, jg)
+	}
+
+	for path, b := range o.Builds {
+		report.Binaries = append(report.Binaries, &jsonBinary{
+			Path:      path,
+			GoVersion: b.GoVersion,
+			Package:   b.Path,
+			MainModule: &jsonMainModule{
+				Path:    b.Main.Path,
+				Version: b.Main.Version,
+			},
+			Settings: b.Settings,
+		})
+	}
+	sort.Slice(report.Binaries, func(i, j int) bool {
+		return report.Binaries[i].Path < report.Binaries[j].Path
+	})
+
+	// Sort the modules by path, then version, so the output is stable
+	sort.Slice(report.Modules, func(i, j int) bool {
+		a, b := report.Modules[i], report.Modules[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+
+		return module.CompareVersions(a.Version, b.Version) < 0
+	})
+
+	f, err := os.Create(o.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(&report); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitchellh/golicense/config"
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONOutput(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Config   *config.Config
+		Module   *module.Module
+		License  *license.License
+		Err      error
+		Expected *jsonModule
+	}{
+		{
+			"allowed",
+			&config.Config{Allow: []string{"MIT"}},
+			&module.Module{Path: "github.com/example/a", Version: "v1.0.0", Hash: "h1:a"},
+			&license.License{Name: "MIT License", SPDX: "MIT", Source: "github", Confidence: 0.9},
+			nil,
+			&jsonModule{
+				Path:     "github.com/example/a",
+				Version:  "v1.0.0",
+				Hash:     "h1:a",
+				Binaries: []string{"bin/app"},
+				License: &jsonLicense{
+					Name:       "MIT License",
+					SPDX:       "MIT",
+					Source:     "github",
+					Confidence: 0.9,
+				},
+				Allowed: "allowed",
+			},
+		},
+
+		{
+			"denied",
+			&config.Config{Deny: []string{"GPL-3.0"}},
+			&module.Module{Path: "github.com/example/a", Version: "v1.0.0"},
+			&license.License{Name: "GNU General Public License v3.0", SPDX: "GPL-3.0"},
+			nil,
+			&jsonModule{
+				Path:    "github.com/example/a",
+				Version: "v1.0.0",
+				License: &jsonLicense{Name: "GNU General Public License v3.0", SPDX: "GPL-3.0"},
+				Allowed: "denied",
+			},
+		},
+
+		{
+			"no config",
+			nil,
+			&module.Module{Path: "github.com/example/a", Version: "v1.0.0"},
+			&license.License{Name: "MIT License", SPDX: "MIT"},
+			nil,
+			&jsonModule{
+				Path:    "github.com/example/a",
+				Version: "v1.0.0",
+				License: &jsonLicense{Name: "MIT License", SPDX: "MIT"},
+				Allowed: "unknown",
+			},
+		},
+
+		{
+			"replaced",
+			nil,
+			&module.Module{
+				Path:    "github.com/example/a",
+				Version: "v1.0.0",
+				Replace: &module.Module{Path: "github.com/fork/a", Version: "v1.0.1", Hash: "h1:b"},
+			},
+			&license.License{Name: "MIT License", SPDX: "MIT"},
+			nil,
+			&jsonModule{
+				Path:    "github.com/example/a",
+				Version: "v1.0.0",
+				Replace: &jsonReplace{Path: "github.com/fork/a", Version: "v1.0.1", Hash: "h1:b"},
+				License: &jsonLicense{Name: "MIT License", SPDX: "MIT"},
+				Allowed: "unknown",
+			},
+		},
+
+		{
+			"no license",
+			&config.Config{Allow: []string{"MIT"}},
+			&module.Module{Path: "github.com/example/a", Version: "v1.0.0"},
+			nil,
+			nil,
+			&jsonModule{
+				Path:    "github.com/example/a",
+				Version: "v1.0.0",
+				Allowed: "denied",
+			},
+		},
+
+		{
+			"lookup error",
+			nil,
+			&module.Module{Path: "github.com/example/a", Version: "v1.0.0"},
+			nil,
+			errors.New("rate limited"),
+			&jsonModule{
+				Path:    "github.com/example/a",
+				Version: "v1.0.0",
+				Allowed: "unknown",
+				Error:   "rate limited",
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			o := &JSONOutput{
+				Path:   filepath.Join(t.TempDir(), "report.json"),
+				Config: tt.Config,
+				ModuleBinaries: map[string][]string{
+					moduleKey(&module.Module{
+						Path:    "github.com/example/a",
+						Version: "v1.0.0",
+						Hash:    "h1:a",
+					}): {"bin/app"},
+				},
+			}
+			o.Start(tt.Module)
+			o.Finish(tt.Module, tt.License, tt.Err)
+			require.NoError(t, o.Close())
+
+			report := testReadJSONReport(t, o.Path)
+			require.Equal(t, []*jsonModule{tt.Expected}, report.Modules)
+		})
+	}
+}
+
+func TestJSONOutput_sorted(t *testing.T) {
+	o := &JSONOutput{Path: filepath.Join(t.TempDir(), "report.json")}
+	for _, m := range []*module.Module{
+		{Path: "github.com/example/b", Version: "v1.0.0"},
+		{Path: "github.com/example/a", Version: "v1.10.0"},
+		{Path: "github.com/example/a", Version: "v1.9.0"},
+	} {
+		o.Finish(m, nil, nil)
+	}
+	require.NoError(t, o.Close())
+
+	var actual []string
+	for _, m := range testReadJSONReport(t, o.Path).Modules {
+		actual = append(actual, m.Path+"@"+m.Version)
+	}
+	require.Equal(t, []string{
+		"github.com/example/a@v1.9.0",
+		"github.com/example/a@v1.10.0",
+		"github.com/example/b@v1.0.0",
+	}, actual)
+}
+
+func TestJSONOutput_warned(t *testing.T) {
+	// Licenses in the warn list are only marked if they're neither
+	// allowed nor denied.
+	o := &JSONOutput{
+		Path: filepath.Join(t.TempDir(), "report.json"),
+		Config: &config.Config{
+			Allow: []string{"MIT"},
+			Deny:  []string{"GPL-3.0"},
+			Warn:  []string{"MIT", "GPL-3.0", "MPL-2.0"},
+		},
+	}
+	licenses := map[string]string{
+		"github.com/example/allowed": "MIT",
+		"github.com/example/denied":  "GPL-3.0",
+		"github.com/example/warned":  "MPL-2.0",
+		"github.com/example/unknown": "Apache-2.0",
+	}
+	for path, id := range licenses {
+		o.Finish(&module.Module{Path: path, Version: "v1.0.0"}, &license.License{SPDX: id}, nil)
+	}
+	require.NoError(t, o.Close())
+
+	actual := make(map[string]bool)
+	for _, m := range testReadJSONReport(t, o.Path).Modules {
+		actual[m.Path] = m.Warned
+	}
+	require.Equal(t, map[string]bool{
+		"github.com/example/allowed": false,
+		"github.com/example/denied":  false,
+		"github.com/example/warned":  true,
+		"github.com/example/unknown": false,
+	}, actual)
+}
+
+// testReadJSONReport decodes the report written by a JSONOutput.
+func testReadJSONReport(t *testing.T, path string) *jsonReport {
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	var report jsonReport
+	require.NoError(t, json.Unmarshal(data, &report))
+	return &report
+}
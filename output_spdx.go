@@ -0,0 +1,367 @@
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+)
+
+// SPDXOutput writes the results of license lookups as an SPDX 2.3
+// document. The binaries are described as the root packages and every
+// module is a package that the binaries it was found in depend on.
+type SPDXOutput struct {
+	// Path is the path to the file to write. This will be overwritten if
+	// it exists.
+	Path string
+
+	// Format is the SPDX serialization format, either "json" or
+	// "tag-value". If this is empty, the format is determined by the
+	// extension of Path: ".json" for JSON, tag-value otherwise.
+	Format string
+
+	// Binaries is the list of paths to the binaries that were analyzed.
+	Binaries []string
+
+	// ModuleBinaries are the paths of the binaries that each module was
+	// found in, keyed by moduleKey. If this is nil, every module is
+	// assumed to be in every binary.
+	ModuleBinaries map[string][]string
+
+	modules map[*module.Module]lookupResult
+	lock    sync.Mutex
+}
+
+// Start implements Output
+func (o *SPDXOutput) Start(m *module.Module) {}
+
+// Update implements Output
+func (o *SPDXOutput) Update(m *module.Module, t license.StatusType, msg string) {}
+
+// Finish implements Output
+func (o *SPDXOutput) Finish(m *module.Module, l *license.License, err error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if o.modules == nil {
+		o.modules = make(map[*module.Module]lookupResult)
+	}
+
+	o.modules[m] = lookupResult{License: l, Err: err}
+}
+
+// Close implements Output
+func (o *SPDXOutput) Close() error {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	doc, err := o.document()
+	if err != nil {
+		return err
+	}
+
+	format := o.Format
+	if format == "" {
+		format = "tag-value"
+		if filepath.Ext(o.Path) == ".json" {
+			format = "json"
+		}
+	}
+
+	f, err := os.Create(o.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	switch format {
+	case "json":
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(doc)
+
+	case "tag-value":
+		err = doc.writeTagValue(f)
+
+	default:
+		err = fmt.Errorf("unknown SPDX format %q, must be 'json' or 'tag-value'", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+// document builds the SPDX document from the recorded results.
+//
+// lock must be held.
+func (o *SPDXOutput) document() (*spdxDocument, error) {
+	name := "golicense"
+	if len(o.Binaries) == 1 {
+		name = filepath.Base(o.Binaries[0])
+	}
+
+	// The namespace must be unique per document so we generate a random
+	// UUID for it.
+	uuid, err := newUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &spdxDocument{
+		SPDXVersion: "SPDX-2.3",
+		DataLicense: "CC0-1.0",
+		SPDXID:      "SPDXRef-DOCUMENT",
+		Name:        name,
+		DocumentNamespace: fmt.Sprintf(
+			"https://spdx.org/spdxdocs/%s-%s",
+			spdxIDRe.ReplaceAllString(name, "-"), uuid),
+		CreationInfo: spdxCreationInfo{
+			Created:  time.Now().UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: golicense"},
+		},
+	}
+
+	ids := make(map[string]struct{})
+	binIDs := make(map[string]string)
+	for _, bin := range o.Binaries {
+		id := spdxID(ids, "SPDXRef-Binary-"+filepath.Base(bin))
+		binIDs[bin] = id
+		doc.Packages = append(doc.Packages, &spdxPackage{
+			Name:             filepath.Base(bin),
+			SPDXID:           id,
+			DownloadLocation: "NOASSERTION",
+			LicenseConcluded: "NOASSERTION",
+			LicenseDeclared:  "NOASSERTION",
+			CopyrightText:    "NOASSERTION",
+			PrimaryPurpose:   "APPLICATION",
+		})
+		doc.Relationships = append(doc.Relationships, &spdxRelationship{
+			Element: doc.SPDXID,
+			Type:    "DESCRIBES",
+			Related: id,
+		})
+	}
+
+	// Sort the modules so that the document is stable
+	mods := make([]*module.Module, 0, len(o.modules))
+	for m := range o.modules {
+		mods = append(mods, m)
+	}
+	sort.Slice(mods, func(i, j int) bool {
+		if mods[i].Path != mods[j].Path {
+			return mods[i].Path < mods[j].Path
+		}
+
+		return mods[i].Version < mods[j].Version
+	})
+
+	for _, m := range mods {
+		// Packages describe the source that was built, which is the
+		// replacement for modules replaced by another module.
+		r, src := o.modules[m], m.Source()
+		pkg := &spdxPackage{
+			Name:             src.FullPath(),
+			SPDXID:           spdxID(ids, "SPDXRef-Package-"+src.FullPath()+"-"+src.Version),
+			Version:          src.Version,
+			DownloadLocation: "NOASSERTION",
+			LicenseConcluded: "NOASSERTION",
+			LicenseDeclared:  "NOASSERTION",
+			CopyrightText:    "NOASSERTION",
+			PrimaryPurpose:   "LIBRARY",
+			ExternalRefs: []*spdxExternalRef{
+				{
+					Category: "PACKAGE-MANAGER",
+					Type:     "purl",
+					Locator:  packageURL(&src),
+				},
+			},
+		}
+		if m.LocalReplace() {
+			pkg.SourceInfo = "replaced by local directory " + m.Replace.Path
+		} else if m.Replace != nil {
+			pkg.SourceInfo = fmt.Sprintf("replaces %s %s", m.Path, m.Version)
+		}
+		if r.License != nil && r.License.SPDX != "" {
+			pkg.LicenseConcluded = r.License.SPDX
+		}
+		// The h1 hash is a hash of the module's file tree as recorded in
+		// go.sum, not a checksum of any file, so it's only a comment.
+		var comments []string
+		if src.Hash != "" {
+			comments = append(comments, "Go module hash: "+src.Hash)
+		}
+		if r.Err != nil {
+			comments = append(comments, fmt.Sprintf("License lookup error: %s", r.Err))
+		}
+		pkg.Comment = strings.Join(comments, "\n")
+
+		doc.Packages = append(doc.Packages, pkg)
+		for _, bin := range moduleBinaries(m, o.ModuleBinaries, o.Binaries) {
+			doc.Relationships = append(doc.Relationships, &spdxRelationship{
+				Element: binIDs[bin],
+				Type:    "DEPENDS_ON",
+				Related: pkg.SPDXID,
+			})
+		}
+	}
+
+	return doc, nil
+}
+
+// spdxID returns a valid, unique SPDX identifier based on the given
+// candidate value. ids is the set of identifiers already in use and is
+// updated with the result.
+func spdxID(ids map[string]struct{}, v string) string {
+	v = spdxIDRe.ReplaceAllString(v, "-")
+	result := v
+	for i := 2; ; i++ {
+		if _, ok := ids[result]; !ok {
+			break
+		}
+
+		result = fmt.Sprintf("%s-%d", v, i)
+	}
+
+	ids[result] = struct{}{}
+	return result
+}
+
+// packageURL returns the package URL (purl) for a Go module, such as
+// "pkg:golang/github.com/mitchellh/golicense@v1.0.0". The path includes
+// the major version suffix, see module.Module.FullPath.
+func packageURL(m *module.Module) string {
+	parts := strings.Split(m.FullPath(), "/")
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+
+	result := "pkg:golang/" + strings.Join(parts, "/")
+	if m.Version != "" {
+		result += "@" + url.PathEscape(m.Version)
+	}
+
+	return result
+}
+
+// newUUID returns a random (version 4) UUID string.
+func newUUID() (string, error) {
+	var uuid [16]byte
+	if _, err := rand.Read(uuid[:]); err != nil {
+		return "", err
+	}
+	uuid[6] = (uuid[6] & 0x0f) | 0x40
+	uuid[8] = (uuid[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x",
+		uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:]), nil
+}
+
+// spdxIDRe matches the characters that are not allowed in an SPDX ID.
+var spdxIDRe = regexp.MustCompile(`[^a-zA-Z0-9.-]+`)
+
+// spdxDocument is an SPDX 2.3 document. The field names and JSON tags
+// follow the SPDX JSON schema.
+type spdxDocument struct {
+	SPDXVersion       string              `json:"spdxVersion"`
+	DataLicense       string              `json:"dataLicense"`
+	SPDXID            string              `json:"SPDXID"`
+	Name              string              `json:"name"`
+	DocumentNamespace string              `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo    `json:"creationInfo"`
+	Packages          []*spdxPackage      `json:"packages"`
+	Relationships     []*spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name             string             `json:"name"`
+	SPDXID           string             `json:"SPDXID"`
+	Version          string             `json:"versionInfo,omitempty"`
+	DownloadLocation string             `json:"downloadLocation"`
+	FilesAnalyzed    bool               `json:"filesAnalyzed"`
+	LicenseConcluded string             `json:"licenseConcluded"`
+	LicenseDeclared  string             `json:"licenseDeclared"`
+	CopyrightText    string             `json:"copyrightText"`
+	SourceInfo       string             `json:"sourceInfo,omitempty"`
+	Comment          string             `json:"comment,omitempty"`
+	PrimaryPurpose   string             `json:"primaryPackagePurpose,omitempty"`
+	ExternalRefs     []*spdxExternalRef `json:"externalRefs,omitempty"`
+}
+
+type spdxExternalRef struct {
+	Category string `json:"referenceCategory"`
+	Type     string `json:"referenceType"`
+	Locator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	Element string `json:"spdxElementId"`
+	Type    string `json:"relationshipType"`
+	Related string `json:"relatedSpdxElement"`
+}
+
+// writeTagValue writes the document in the SPDX tag-value format.
+func (d *spdxDocument) writeTagValue(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "SPDXVersion: %s\n", d.SPDXVersion)
+	fmt.Fprintf(bw, "DataLicense: %s\n", d.DataLicense)
+	fmt.Fprintf(bw, "SPDXID: %s\n", d.SPDXID)
+	fmt.Fprintf(bw, "DocumentName: %s\n", d.Name)
+	fmt.Fprintf(bw, "DocumentNamespace: %s\n", d.DocumentNamespace)
+	for _, c := range d.CreationInfo.Creators {
+		fmt.Fprintf(bw, "Creator: %s\n", c)
+	}
+	fmt.Fprintf(bw, "Created: %s\n", d.CreationInfo.Created)
+
+	for _, p := range d.Packages {
+		fmt.Fprintf(bw, "\nPackageName: %s\n", p.Name)
+		fmt.Fprintf(bw, "SPDXID: %s\n", p.SPDXID)
+		if p.Version != "" {
+			fmt.Fprintf(bw, "PackageVersion: %s\n", p.Version)
+		}
+		fmt.Fprintf(bw, "PackageDownloadLocation: %s\n", p.DownloadLocation)
+		fmt.Fprintf(bw, "FilesAnalyzed: %t\n", p.FilesAnalyzed)
+		fmt.Fprintf(bw, "PackageLicenseConcluded: %s\n", p.LicenseConcluded)
+		fmt.Fprintf(bw, "PackageLicenseDeclared: %s\n", p.LicenseDeclared)
+		fmt.Fprintf(bw, "PackageCopyrightText: %s\n", p.CopyrightText)
+		if p.SourceInfo != "" {
+			fmt.Fprintf(bw, "PackageSourceInfo: <text>%s</text>\n", p.SourceInfo)
+		}
+		if p.Comment != "" {
+			fmt.Fprintf(bw, "PackageComment: <text>%s</text>\n", p.Comment)
+		}
+		if p.PrimaryPurpose != "" {
+			fmt.Fprintf(bw, "PrimaryPackagePurpose: %s\n", p.PrimaryPurpose)
+		}
+		for _, r := range p.ExternalRefs {
+			fmt.Fprintf(bw, "ExternalRef: %s %s %s\n", r.Category, r.Type, r.Locator)
+		}
+	}
+
+	if len(d.Relationships) > 0 {
+		bw.WriteString("\n")
+	}
+	for _, r := range d.Relationships {
+		fmt.Fprintf(bw, "Relationship: %s %s %s\n", r.Element, r.Type, r.Related)
+	}
+
+	return bw.Flush()
+}
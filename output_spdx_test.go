@@ -0,0 +1,259 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSPDXOutput(t *testing.T) {
+	github := &module.Module{Path: "github.com/google/go-github", Version: "v18.2.0", Hash: "h1:gh"}
+	under := &module.Module{Path: "github.com/example/a_b", Version: "v1.0.0"}
+	dash := &module.Module{Path: "github.com/example/a-b", Version: "v1.0.0"}
+	failed := &module.Module{Path: "github.com/example/failed", Version: "v0.1.0"}
+	replaced := &module.Module{
+		Path:    "github.com/example/orig",
+		Version: "v1.0.0",
+		Replace: &module.Module{Path: "github.com/fork/orig/v2", Version: "v2.0.1", Hash: "h1:fork"},
+	}
+
+	o := &SPDXOutput{
+		Path:     filepath.Join(t.TempDir(), "sbom.spdx.json"),
+		Binaries: []string{"/a/app", "/b/app"},
+		ModuleBinaries: map[string][]string{
+			moduleKey(github):   {"/a/app", "/b/app"},
+			moduleKey(under):    {"/a/app"},
+			moduleKey(dash):     {"/b/app"},
+			moduleKey(failed):   {"/a/app"},
+			moduleKey(replaced): {"/b/app"},
+		},
+	}
+	o.Finish(github, &license.License{Name: "BSD 3-Clause", SPDX: "BSD-3-Clause"}, nil)
+	o.Finish(under, &license.License{Name: "MIT License", SPDX: "MIT"}, nil)
+	o.Finish(dash, &license.License{Name: "Custom"}, nil)
+	o.Finish(failed, nil, errors.New("rate limited"))
+	o.Finish(replaced, &license.License{Name: "Apache", SPDX: "Apache-2.0"}, nil)
+
+	doc, err := o.document()
+	require.NoError(t, err)
+	require.Equal(t, "SPDX-2.3", doc.SPDXVersion)
+	require.Equal(t, "golicense", doc.Name)
+
+	// Packages are sorted by the module path that was found in the
+	// binary, and identifiers are escaped and made unique.
+	type pkg struct {
+		ID, Name, Version, License, Purl, Comment, SourceInfo string
+	}
+	var actual []pkg
+	for _, p := range doc.Packages {
+		v := pkg{
+			ID:         p.SPDXID,
+			Name:       p.Name,
+			Version:    p.Version,
+			License:    p.LicenseConcluded,
+			Comment:    p.Comment,
+			SourceInfo: p.SourceInfo,
+		}
+		if len(p.ExternalRefs) > 0 {
+			v.Purl = p.ExternalRefs[0].Locator
+		}
+		actual = append(actual, v)
+	}
+	require.Equal(t, []pkg{
+		{ID: "SPDXRef-Binary-app", Name: "app", License: "NOASSERTION"},
+		{ID: "SPDXRef-Binary-app-2", Name: "app", License: "NOASSERTION"},
+		{
+			ID:      "SPDXRef-Package-github.com-example-a-b-v1.0.0",
+			Name:    "github.com/example/a-b",
+			Version: "v1.0.0",
+			License: "NOASSERTION",
+			Purl:    "pkg:golang/github.com/example/a-b@v1.0.0",
+		},
+		{
+			ID:      "SPDXRef-Package-github.com-example-a-b-v1.0.0-2",
+			Name:    "github.com/example/a_b",
+			Version: "v1.0.0",
+			License: "MIT",
+			Purl:    "pkg:golang/github.com/example/a_b@v1.0.0",
+		},
+		{
+			ID:      "SPDXRef-Package-github.com-example-failed-v0.1.0",
+			Name:    "github.com/example/failed",
+			Version: "v0.1.0",
+			License: "NOASSERTION",
+			Purl:    "pkg:golang/github.com/example/failed@v0.1.0",
+			Comment: "License lookup error: rate limited",
+		},
+		{
+			ID:         "SPDXRef-Package-github.com-fork-orig-v2-v2.0.1",
+			Name:       "github.com/fork/orig/v2",
+			Version:    "v2.0.1",
+			License:    "Apache-2.0",
+			Purl:       "pkg:golang/github.com/fork/orig/v2@v2.0.1",
+			Comment:    "Go module hash: h1:fork",
+			SourceInfo: "replaces github.com/example/orig v1.0.0",
+		},
+		{
+			ID:      "SPDXRef-Package-github.com-google-go-github-v18-v18.2.0",
+			Name:    "github.com/google/go-github/v18",
+			Version: "v18.2.0",
+			License: "BSD-3-Clause",
+			Purl:    "pkg:golang/github.com/google/go-github/v18@v18.2.0",
+			Comment: "Go module hash: h1:gh",
+		},
+	}, actual)
+
+	var rels []string
+	for _, r := range doc.Relationships {
+		rels = append(rels, r.Element+" "+r.Type+" "+r.Related)
+	}
+	require.Equal(t, []string{
+		"SPDXRef-DOCUMENT DESCRIBES SPDXRef-Binary-app",
+		"SPDXRef-DOCUMENT DESCRIBES SPDXRef-Binary-app-2",
+		"SPDXRef-Binary-app-2 DEPENDS_ON SPDXRef-Package-github.com-example-a-b-v1.0.0",
+		"SPDXRef-Binary-app DEPENDS_ON SPDXRef-Package-github.com-example-a-b-v1.0.0-2",
+		"SPDXRef-Binary-app DEPENDS_ON SPDXRef-Package-github.com-example-failed-v0.1.0",
+		"SPDXRef-Binary-app-2 DEPENDS_ON SPDXRef-Package-github.com-fork-orig-v2-v2.0.1",
+		"SPDXRef-Binary-app DEPENDS_ON SPDXRef-Package-github.com-google-go-github-v18-v18.2.0",
+		"SPDXRef-Binary-app-2 DEPENDS_ON SPDXRef-Package-github.com-google-go-github-v18-v18.2.0",
+	}, rels)
+}
+
+func TestSPDXOutput_json(t *testing.T) {
+	m := &module.Module{Path: "github.com/example/a", Version: "v1.0.0", Hash: "h1:a"}
+	o := &SPDXOutput{
+		Path:     filepath.Join(t.TempDir(), "sbom.spdx.json"),
+		Binaries: []string{"/bin/app"},
+	}
+	o.Finish(m, &license.License{Name: "MIT License", SPDX: "MIT"}, nil)
+	require.NoError(t, o.Close())
+
+	data, err := ioutil.ReadFile(o.Path)
+	require.NoError(t, err)
+
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &doc))
+	require.Equal(t, "SPDX-2.3", doc["spdxVersion"])
+	require.Equal(t, "SPDXRef-DOCUMENT", doc["SPDXID"])
+	require.Equal(t, "app", doc["name"])
+	require.True(t, strings.HasPrefix(doc["documentNamespace"].(string), "https://spdx.org/spdxdocs/app-"))
+
+	pkgs := doc["packages"].([]interface{})
+	require.Len(t, pkgs, 2)
+	require.Equal(t, map[string]interface{}{
+		"name":                  "github.com/example/a",
+		"SPDXID":                "SPDXRef-Package-github.com-example-a-v1.0.0",
+		"versionInfo":           "v1.0.0",
+		"downloadLocation":      "NOASSERTION",
+		"filesAnalyzed":         false,
+		"licenseConcluded":      "MIT",
+		"licenseDeclared":       "NOASSERTION",
+		"copyrightText":         "NOASSERTION",
+		"comment":               "Go module hash: h1:a",
+		"primaryPackagePurpose": "LIBRARY",
+		"externalRefs": []interface{}{
+			map[string]interface{}{
+				"referenceCategory": "PACKAGE-MANAGER",
+				"referenceType":     "purl",
+				"referenceLocator":  "pkg:golang/github.com/example/a@v1.0.0",
+			},
+		},
+	}, pkgs[1])
+	require.Equal(t, []interface{}{
+		map[string]interface{}{
+			"spdxElementId":      "SPDXRef-DOCUMENT",
+			"relationshipType":   "DESCRIBES",
+			"relatedSpdxElement": "SPDXRef-Binary-app",
+		},
+		map[string]interface{}{
+			"spdxElementId":      "SPDXRef-Binary-app",
+			"relationshipType":   "DEPENDS_ON",
+			"relatedSpdxElement": "SPDXRef-Package-github.com-example-a-v1.0.0",
+		},
+	}, doc["relationships"])
+}
+
+func TestSPDXOutput_tagValue(t *testing.T) {
+	m := &module.Module{Path: "github.com/example/a", Version: "v1.0.0", Hash: "h1:a"}
+	o := &SPDXOutput{
+		Path:     filepath.Join(t.TempDir(), "sbom.spdx"),
+		Binaries: []string{"/bin/app"},
+	}
+	o.Finish(m, nil, errors.New("not found"))
+	require.NoError(t, o.Close())
+
+	data, err := ioutil.ReadFile(o.Path)
+	require.NoError(t, err)
+	actual := string(data)
+
+	for _, line := range []string{
+		"SPDXVersion: SPDX-2.3\n",
+		"DataLicense: CC0-1.0\n",
+		"SPDXID: SPDXRef-DOCUMENT\n",
+		"DocumentName: app\n",
+		"Creator: Tool: golicense\n",
+		"\nPackageName: app\nSPDXID: SPDXRef-Binary-app\n",
+		"PrimaryPackagePurpose: APPLICATION\n",
+		"\nPackageName: github.com/example/a\n" +
+			"SPDXID: SPDXRef-Package-github.com-example-a-v1.0.0\n" +
+			"PackageVersion: v1.0.0\n" +
+			"PackageDownloadLocation: NOASSERTION\n" +
+			"FilesAnalyzed: false\n" +
+			"PackageLicenseConcluded: NOASSERTION\n" +
+			"PackageLicenseDeclared: NOASSERTION\n" +
+			"PackageCopyrightText: NOASSERTION\n" +
+			"PackageComment: <text>Go module hash: h1:a\nLicense lookup error: not found</text>\n" +
+			"PrimaryPackagePurpose: LIBRARY\n" +
+			"ExternalRef: PACKAGE-MANAGER purl pkg:golang/github.com/example/a@v1.0.0\n",
+		"\nRelationship: SPDXRef-DOCUMENT DESCRIBES SPDXRef-Binary-app\n" +
+			"Relationship: SPDXRef-Binary-app DEPENDS_ON SPDXRef-Package-github.com-example-a-v1.0.0\n",
+	} {
+		require.Contains(t, actual, line)
+	}
+	require.NotContains(t, actual, "PackageChecksum")
+}
+
+func TestPackageURL(t *testing.T) {
+	cases := []struct {
+		Module   module.Module
+		Expected string
+	}{
+		{
+			module.Module{Path: "github.com/example/a", Version: "v1.0.0"},
+			"pkg:golang/github.com/example/a@v1.0.0",
+		},
+
+		{
+			module.Module{Path: "github.com/google/go-github", Version: "v18.0.0"},
+			"pkg:golang/github.com/google/go-github/v18@v18.0.0",
+		},
+
+		{
+			module.Module{Path: "github.com/example/a", Version: "v2.0.0+incompatible"},
+			"pkg:golang/github.com/example/a@v2.0.0+incompatible",
+		},
+
+		{
+			module.Module{Path: "gopkg.in/yaml.v2", Version: "v2.4.0"},
+			"pkg:golang/gopkg.in/yaml.v2@v2.4.0",
+		},
+
+		{
+			module.Module{Path: "example.com/a b", Version: "v1.0.0"},
+			"pkg:golang/example.com/a%20b@v1.0.0",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Expected, func(t *testing.T) {
+			require.Equal(t, tt.Expected, packageURL(&tt.Module))
+		})
+	}
+}
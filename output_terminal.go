@@ -7,6 +7,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/gosuri/uilive"
@@ -33,26 +34,44 @@ type TermOutput struct {
 	// aligned.
 	Modules []module.Module
 
+	// Binaries is the list of paths to the binaries that were analyzed.
+	// If there are several, the results are written in a section per
+	// binary on Close instead of as each lookup finishes.
+	Binaries []string
+
+	// ModuleBinaries are the paths of the binaries that each module was
+	// found in, keyed by moduleKey.
+	ModuleBinaries map[string][]string
+
+	// Groups are the modules of all binaries grouped by path. If any
+	// module has several versions, they are listed on Close.
+	Groups []*module.Group
+
 	// Plain, if true, will use the plain output vs the live updating output.
 	// TermOutput will always use Plain output if the Out configured above
 	// is not a TTY.
 	Plain bool
 
-	// Verbose will log all status updates in plain mode. This has no effect
-	// in non-plain mode currently.
+	// Verbose will log all status updates in plain mode and show where
+	// each license came from.
 	Verbose bool
 
 	modules   map[string]string
+	results   map[string]string
 	moduleMax int
-	exitCode  int
+	summary   summary
 	lineMax   int
 	live      *uilive.Writer
 	once      sync.Once
 	lock      sync.Mutex
 }
 
+// ExitCode returns the exit code for the results. See the Exit constants.
 func (o *TermOutput) ExitCode() int {
-	return o.exitCode
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	return o.summary.ExitCode()
 }
 
 // Start implements Output
@@ -65,7 +84,7 @@ func (o *TermOutput) Start(m *module.Module) {
 
 	o.lock.Lock()
 	defer o.lock.Unlock()
-	o.modules[m.Path] = fmt.Sprintf("%s %s starting...", iconNormal, o.paddedModule(m))
+	o.modules[moduleKey(m)] = fmt.Sprintf("%s %s starting...", iconNormal, o.paddedModule(m))
 	o.updateLiveOutput()
 }
 
@@ -76,8 +95,7 @@ func (o *TermOutput) Update(m *module.Module, t license.StatusType, msg string)
 	// In plain & verbose mode, we output every status message, but in normal
 	// plain mode we ignore all status updates.
 	if o.Plain && o.Verbose {
-		fmt.Fprintf(o.Out, fmt.Sprintf(
-			"%s %s\n", o.paddedModule(m), msg))
+		fmt.Fprintf(o.Out, "%s %s\n", o.paddedModule(m), msg)
 	}
 
 	if o.Plain {
@@ -101,7 +119,7 @@ func (o *TermOutput) Update(m *module.Module, t license.StatusType, msg string)
 
 	o.lock.Lock()
 	defer o.lock.Unlock()
-	o.modules[m.Path] = colorFunc("%s%s %s", icon, o.paddedModule(m), msg)
+	o.modules[moduleKey(m)] = colorFunc("%s%s %s", icon, o.paddedModule(m), msg)
 	o.updateLiveOutput()
 }
 
@@ -111,42 +129,98 @@ func (o *TermOutput) Finish(m *module.Module, l *license.License, err error) {
 
 	var colorFunc func(string, ...interface{}) string = fmt.Sprintf
 	icon := iconNormal
-	if o.Config != nil {
-		state := o.Config.Allowed(l)
-		switch state {
-		case config.StateAllowed:
+	var notes []string
+	result, exception := checkModule(o.Config, m, l, err)
+	switch result {
+	case outcomeAllowed:
+		if o.Config != nil {
 			colorFunc = color.GreenString
 			icon = iconSuccess
+		}
 
-		case config.StateDenied:
-			colorFunc = color.RedString
-			icon = iconError
-			o.exitCode = 1
+	case outcomeWarned:
+		colorFunc = color.YellowString
+		icon = iconWarning
+		notes = append(notes, "license is in the warn list")
 
-		case config.StateUnknown:
-			if len(o.Config.Allow) > 0 || len(o.Config.Deny) > 0 {
-				colorFunc = color.YellowString
-				icon = iconWarning
-				o.exitCode = 1
-			}
+	case outcomeDenied:
+		colorFunc = color.RedString
+		icon = iconError
+
+	case outcomeUnlicensed, outcomeError:
+		// These are only highlighted as warnings if fail_on ignores them
+		colorFunc = color.RedString
+		icon = iconError
+		if !o.summary.Fails(result) {
+			colorFunc = color.YellowString
+			icon = iconWarning
 		}
+
+	case outcomeUnknown:
+		if o.summary.Policy || o.summary.Fails(result) {
+			colorFunc = color.YellowString
+			icon = iconWarning
+		}
+	}
+	if exception != nil {
+		notes = append(notes, exceptionNote(exception))
+	}
+
+	o.lock.Lock()
+	o.summary.Add(result, l)
+	o.lock.Unlock()
+
+	// Warnings don't affect the exit code but we highlight them if the
+	// license is otherwise fine.
+	text := l.String()
+	if l == nil && err != nil {
+		text = fmt.Sprintf("ERROR: %s", err)
+	}
+	if l != nil && len(l.Warnings) > 0 {
+		notes = append(append([]string(nil), l.Warnings...), notes...)
+		if icon == iconNormal || icon == iconSuccess {
+			colorFunc = color.YellowString
+			icon = iconWarning
+		}
+	}
+	if m.Replace != nil {
+		notes = append([]string{replaceNote(m)}, notes...)
+	}
+	if len(notes) > 0 {
+		text += fmt.Sprintf(" (%s)", strings.Join(notes, "; "))
+	}
+	if o.Verbose && l != nil {
+		text += fmt.Sprintf(" [%s]", provenanceNote(l))
 	}
 	if icon != "" {
 		icon += " "
 	}
 
+	if o.sections() {
+		o.lock.Lock()
+		defer o.lock.Unlock()
+
+		o.results[moduleKey(m)] = fmt.Sprintf("%s %s", o.paddedModule(m), text)
+		if !o.Plain {
+			o.results[moduleKey(m)] = colorFunc("%s%s %s", icon, o.paddedModule(m), text)
+			delete(o.modules, moduleKey(m))
+			o.updateLiveOutput()
+		}
+
+		return
+	}
+
 	if o.Plain {
-		fmt.Fprintf(o.Out, fmt.Sprintf(
-			"%s %s\n", o.paddedModule(m), l.String()))
+		fmt.Fprintf(o.Out, "%s %s\n", o.paddedModule(m), text)
 		return
 	}
 
 	o.lock.Lock()
 	defer o.lock.Unlock()
-	delete(o.modules, m.Path)
+	delete(o.modules, moduleKey(m))
 	o.pauseLive(func() {
 		o.live.Write([]byte(colorFunc(
-			"%s%s %s\n", icon, o.paddedModule(m), l.String())))
+			"%s%s %s\n", icon, o.paddedModule(m), text)))
 	})
 }
 
@@ -158,8 +232,129 @@ func (o *TermOutput) Close() error {
 	if o.live != nil {
 		o.live.Stop()
 	}
+	if o.sections() {
+		o.writeSections()
+	}
+	o.writeSkew()
 
-	return nil
+	return o.summary.Write(o.Out)
+}
+
+// writeSkew writes the modules that have several versions or hashes
+// across binaries, with the binaries that use each version.
+//
+// lock must be held.
+func (o *TermOutput) writeSkew() {
+	var groups []*module.Group
+	for _, g := range o.Groups {
+		if g.Skew() || g.HashMismatch() {
+			groups = append(groups, g)
+		}
+	}
+	if len(groups) == 0 {
+		return
+	}
+
+	fmt.Fprintf(o.Out, "\n%s\n", color.YellowString(
+		"%s Version skew in %d module(s):", iconWarning, len(groups)))
+	for _, g := range groups {
+		note := ""
+		if g.HashMismatch() {
+			note = " (same version with different hashes)"
+		}
+
+		fmt.Fprintf(o.Out, "  %s%s\n", g.Path, note)
+		for i := range g.Modules {
+			m := &g.Modules[i]
+			version := m.Version
+			if m.Replace != nil {
+				version += " (" + replaceNote(m) + ")"
+			}
+			if g.HashMismatch() && m.Hash != "" {
+				version += " " + m.Hash
+			}
+
+			fmt.Fprintf(o.Out, "    %s: %s\n",
+				version, strings.Join(o.ModuleBinaries[moduleKey(m)], ", "))
+		}
+	}
+}
+
+// sections returns true if the results are written in a section per
+// binary.
+func (o *TermOutput) sections() bool {
+	return len(o.Binaries) > 1
+}
+
+// writeSections writes the results of the modules of each binary.
+//
+// lock must be held.
+func (o *TermOutput) writeSections() {
+	for i, bin := range o.Binaries {
+		if i > 0 {
+			fmt.Fprintln(o.Out)
+		}
+		fmt.Fprintf(o.Out, "%s:\n", bin)
+
+		var keys []string
+		for k := range o.results {
+			if containsString(o.ModuleBinaries[k], bin) {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		if len(keys) == 0 {
+			fmt.Fprintln(o.Out, "  no modules checked")
+		}
+		for _, k := range keys {
+			fmt.Fprintf(o.Out, "  %s\n", o.results[k])
+		}
+	}
+}
+
+// exceptionNote returns a human-friendly note about an exception that
+// applies to a module.
+func exceptionNote(e *config.Exception) string {
+	if e.Expired(time.Now()) {
+		return fmt.Sprintf("exception expired on %s: %s", e.Expires, e.Reason)
+	}
+
+	return fmt.Sprintf("accepted by exception: %s", e.Reason)
+}
+
+// replaceNote returns a human-friendly note about the replacement of a
+// module.
+func replaceNote(m *module.Module) string {
+	if m.LocalReplace() {
+		return "replaced by local directory " + m.Replace.Path
+	}
+
+	return fmt.Sprintf("replaced by %s %s", m.Replace.Path, m.Replace.Version)
+}
+
+// provenanceNote returns a human-friendly note about where a license
+// came from.
+func provenanceNote(l *license.License) string {
+	source := l.Source
+	if source == "" {
+		source = "unknown"
+	}
+
+	parts := []string{"source: " + source}
+	if l.Confidence > 0 {
+		parts = append(parts, "confidence: "+confidenceString(l.Confidence))
+	}
+	if len(l.Translations) > 0 {
+		parts = append(parts, "translated to: "+strings.Join(l.Translations, " -> "))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// confidenceString formats a license detection score as a percentage.
+func confidenceString(v float32) string {
+	return fmt.Sprintf("%.1f%%", v*100)
 }
 
 // paddedModule returns the name of the module padded so that they align nicely.
@@ -220,6 +415,13 @@ func (o *TermOutput) init() {
 	if o.modules == nil {
 		o.modules = make(map[string]string)
 	}
+	if o.results == nil {
+		o.results = make(map[string]string)
+	}
+	if o.Config != nil {
+		o.summary.Policy = len(o.Config.Allow) > 0 || len(o.Config.Deny) > 0
+		o.summary.FailOn = o.Config.FailOn
+	}
 
 	// Calculate the maximum module length
 	for _, m := range o.Modules {
@@ -2,8 +2,10 @@ package main
 
 import (
 	"fmt"
+	"path/filepath"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/360EntSecGroup-Skylar/excelize"
@@ -22,6 +24,14 @@ type XLSXOutput struct {
 	// if a license is allowed or not.
 	Config *config.Config
 
+	// Binaries is the list of paths to the binaries that were analyzed.
+	// If there are several, a sheet is written for each binary.
+	Binaries []string
+
+	// ModuleBinaries are the paths of the binaries or other inputs that
+	// each module was found in, keyed by moduleKey.
+	ModuleBinaries map[string][]string
+
 	modules map[*module.Module]interface{}
 	lock    sync.Mutex
 }
@@ -52,57 +62,113 @@ func (o *XLSXOutput) Close() error {
 	o.lock.Lock()
 	defer o.lock.Unlock()
 
-	const s = "Sheet1"
 	f := excelize.NewFile()
 
+	// Create all our styles
+	styles := &xlsxStyles{}
+	styles.Red, _ = f.NewStyle(`{"fill":{"type":"pattern","pattern":1,"color":["#FFCCCC"]}}`)
+	styles.Yellow, _ = f.NewStyle(`{"fill":{"type":"pattern","pattern":1,"color":["#FFC107"]}}`)
+	styles.Green, _ = f.NewStyle(`{"fill":{"type":"pattern","pattern":1,"color":["#9CCC65"]}}`)
+
+	// Sort the modules by name, then version
+	mods := make([]*module.Module, 0, len(o.modules))
+	for m := range o.modules {
+		mods = append(mods, m)
+	}
+	sort.Slice(mods, func(i, j int) bool {
+		if mods[i].Path != mods[j].Path {
+			return mods[i].Path < mods[j].Path
+		}
+
+		return module.CompareVersions(mods[i].Version, mods[j].Version) < 0
+	})
+
+	// With a single binary all modules are on one sheet. With several
+	// binaries the first sheet is a summary of all modules with a matrix
+	// of the binaries that use each, followed by a sheet per binary.
+	if len(o.Binaries) <= 1 {
+		o.writeSheet(f, "Sheet1", mods, styles)
+	} else {
+		const summary = "Summary"
+		f.SetSheetName("Sheet1", summary)
+		o.writeSheet(f, summary, mods, styles)
+		o.writeMatrix(f, summary, mods)
+
+		// Sheet names are compared case-insensitively by Excel.
+		names := map[string]struct{}{strings.ToLower(summary): {}}
+		for _, bin := range o.Binaries {
+			var binMods []*module.Module
+			for _, m := range mods {
+				if containsString(o.ModuleBinaries[moduleKey(m)], bin) {
+					binMods = append(binMods, m)
+				}
+			}
+
+			s := xlsxSheetName(names, bin)
+			f.NewSheet(s)
+			o.writeSheet(f, s, binMods, styles)
+		}
+	}
+
+	// Save
+	if err := f.SaveAs(o.Path); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// writeSheet writes the results of the modules to a sheet.
+func (o *XLSXOutput) writeSheet(f *excelize.File, s string, mods []*module.Module, styles *xlsxStyles) {
 	// Headers
 	f.SetCellValue(s, "A1", "Dependency")
 	f.SetCellValue(s, "B1", "Version")
 	f.SetCellValue(s, "C1", "SPDX ID")
 	f.SetCellValue(s, "D1", "License")
 	f.SetCellValue(s, "E1", "Allowed")
+	f.SetCellValue(s, "F1", "Exception")
+	f.SetCellValue(s, "G1", "Source")
+	f.SetCellValue(s, "H1", "Confidence")
+	f.SetCellValue(s, "I1", "Translated To")
+	f.SetCellValue(s, "J1", "Replaced By")
+	f.SetCellValue(s, "K1", "Binaries")
 	f.SetColWidth(s, "A", "A", 40)
 	f.SetColWidth(s, "B", "B", 20)
 	f.SetColWidth(s, "C", "C", 20)
 	f.SetColWidth(s, "D", "D", 40)
 	f.SetColWidth(s, "E", "E", 10)
-
-	// Create all our styles
-	redStyle, _ := f.NewStyle(`{"fill":{"type":"pattern","pattern":1,"color":["#FFCCCC"]}}`)
-	yellowStyle, _ := f.NewStyle(`{"fill":{"type":"pattern","pattern":1,"color":["#FFC107"]}}`)
-	greenStyle, _ := f.NewStyle(`{"fill":{"type":"pattern","pattern":1,"color":["#9CCC65"]}}`)
-
-	// Sort the modules by name
-	keys := make([]string, 0, len(o.modules))
-	index := map[string]*module.Module{}
-	for m := range o.modules {
-		keys = append(keys, m.Path)
-		index[m.Path] = m
-	}
-	sort.Strings(keys)
+	f.SetColWidth(s, "F", "F", 60)
+	f.SetColWidth(s, "G", "G", 40)
+	f.SetColWidth(s, "H", "H", 12)
+	f.SetColWidth(s, "I", "I", 40)
+	f.SetColWidth(s, "J", "J", 40)
+	f.SetColWidth(s, "K", "K", 60)
 
 	// Go through each module and output it into the spreadsheet
-	for i, k := range keys {
+	for i, m := range mods {
 		row := strconv.FormatInt(int64(i+2), 10)
 
-		m := index[k]
 		f.SetCellValue(s, "A"+row, m.Path)
 		f.SetCellValue(s, "B"+row, m.Version)
 		f.SetCellValue(s, "E"+row, "unknown")
-		f.SetCellStyle(s, "A"+row, "A"+row, yellowStyle)
-		f.SetCellStyle(s, "B"+row, "B"+row, yellowStyle)
-		f.SetCellStyle(s, "C"+row, "C"+row, yellowStyle)
-		f.SetCellStyle(s, "D"+row, "D"+row, yellowStyle)
-		f.SetCellStyle(s, "E"+row, "E"+row, yellowStyle)
+		if r := m.Replace; r != nil {
+			f.SetCellValue(s, "J"+row, strings.TrimSpace(r.Path+" "+r.Version))
+		}
+		f.SetCellValue(s, "K"+row, strings.Join(o.ModuleBinaries[moduleKey(m)], ", "))
+		f.SetCellStyle(s, "A"+row, "A"+row, styles.Yellow)
+		f.SetCellStyle(s, "B"+row, "B"+row, styles.Yellow)
+		f.SetCellStyle(s, "C"+row, "C"+row, styles.Yellow)
+		f.SetCellStyle(s, "D"+row, "D"+row, styles.Yellow)
+		f.SetCellStyle(s, "E"+row, "E"+row, styles.Yellow)
 
 		raw := o.modules[m]
 		if raw == nil {
 			f.SetCellValue(s, "D"+row, "no")
-			f.SetCellStyle(s, "A"+row, "A"+row, redStyle)
-			f.SetCellStyle(s, "B"+row, "B"+row, redStyle)
-			f.SetCellStyle(s, "C"+row, "C"+row, redStyle)
-			f.SetCellStyle(s, "D"+row, "D"+row, redStyle)
-			f.SetCellStyle(s, "E"+row, "E"+row, redStyle)
+			f.SetCellStyle(s, "A"+row, "A"+row, styles.Red)
+			f.SetCellStyle(s, "B"+row, "B"+row, styles.Red)
+			f.SetCellStyle(s, "C"+row, "C"+row, styles.Red)
+			f.SetCellStyle(s, "D"+row, "D"+row, styles.Red)
+			f.SetCellStyle(s, "E"+row, "E"+row, styles.Red)
 			continue
 		}
 
@@ -110,46 +176,123 @@ func (o *XLSXOutput) Close() error {
 		if err, ok := raw.(error); ok {
 			f.SetCellValue(s, "D"+row, fmt.Sprintf("ERROR: %s", err))
 			f.SetCellValue(s, "E"+row, "no")
-			f.SetCellStyle(s, "A"+row, "A"+row, redStyle)
-			f.SetCellStyle(s, "B"+row, "B"+row, redStyle)
-			f.SetCellStyle(s, "C"+row, "C"+row, redStyle)
-			f.SetCellStyle(s, "D"+row, "D"+row, redStyle)
-			f.SetCellStyle(s, "E"+row, "E"+row, redStyle)
+			f.SetCellStyle(s, "A"+row, "A"+row, styles.Red)
+			f.SetCellStyle(s, "B"+row, "B"+row, styles.Red)
+			f.SetCellStyle(s, "C"+row, "C"+row, styles.Red)
+			f.SetCellStyle(s, "D"+row, "D"+row, styles.Red)
+			f.SetCellStyle(s, "E"+row, "E"+row, styles.Red)
 			continue
 		}
 
 		// If the value is a license, then mark the license
 		if lic, ok := raw.(*license.License); ok {
 			if lic != nil {
-				f.SetCellValue(s, fmt.Sprintf("C%d", i+2), lic.SPDX)
+				f.SetCellValue(s, "C"+row, lic.SPDX)
+				f.SetCellValue(s, "G"+row, lic.Source)
+				if lic.Confidence > 0 {
+					f.SetCellValue(s, "H"+row, confidenceString(lic.Confidence))
+				}
+				f.SetCellValue(s, "I"+row, strings.Join(lic.Translations, " -> "))
 			}
-			f.SetCellValue(s, fmt.Sprintf("D%d", i+2), lic.String())
+			f.SetCellValue(s, "D"+row, lic.String())
 			if o.Config != nil {
-				switch o.Config.Allowed(lic) {
+				state, e := o.Config.ModuleAllowed(m, lic)
+				if e != nil {
+					f.SetCellValue(s, "F"+row, exceptionNote(e))
+				}
+
+				switch state {
 				case config.StateAllowed:
-					f.SetCellValue(s, fmt.Sprintf("E%d", i+2), "yes")
-					f.SetCellStyle(s, "A"+row, "A"+row, greenStyle)
-					f.SetCellStyle(s, "B"+row, "B"+row, greenStyle)
-					f.SetCellStyle(s, "C"+row, "C"+row, greenStyle)
-					f.SetCellStyle(s, "D"+row, "D"+row, greenStyle)
-					f.SetCellStyle(s, "E"+row, "E"+row, greenStyle)
+					f.SetCellValue(s, "E"+row, "yes")
+					f.SetCellStyle(s, "A"+row, "A"+row, styles.Green)
+					f.SetCellStyle(s, "B"+row, "B"+row, styles.Green)
+					f.SetCellStyle(s, "C"+row, "C"+row, styles.Green)
+					f.SetCellStyle(s, "D"+row, "D"+row, styles.Green)
+					f.SetCellStyle(s, "E"+row, "E"+row, styles.Green)
 
 				case config.StateDenied:
-					f.SetCellValue(s, fmt.Sprintf("E%d", i+2), "no")
-					f.SetCellStyle(s, "A"+row, "A"+row, redStyle)
-					f.SetCellStyle(s, "B"+row, "B"+row, redStyle)
-					f.SetCellStyle(s, "C"+row, "C"+row, redStyle)
-					f.SetCellStyle(s, "D"+row, "D"+row, redStyle)
-					f.SetCellStyle(s, "E"+row, "E"+row, redStyle)
+					f.SetCellValue(s, "E"+row, "no")
+					f.SetCellStyle(s, "A"+row, "A"+row, styles.Red)
+					f.SetCellStyle(s, "B"+row, "B"+row, styles.Red)
+					f.SetCellStyle(s, "C"+row, "C"+row, styles.Red)
+					f.SetCellStyle(s, "D"+row, "D"+row, styles.Red)
+					f.SetCellStyle(s, "E"+row, "E"+row, styles.Red)
+
+				default:
+					// Unknown rows are already highlighted in yellow
+					if o.Config.Warned(lic) {
+						f.SetCellValue(s, "E"+row, "warn")
+					}
 				}
 			}
 		}
 	}
+}
 
-	// Save
-	if err := f.SaveAs(o.Path); err != nil {
-		return err
+// writeMatrix writes a column for each binary to a sheet written by
+// writeSheet, marking the modules that each binary uses.
+func (o *XLSXOutput) writeMatrix(f *excelize.File, s string, mods []*module.Module) {
+	for j, bin := range o.Binaries {
+		// The matrix starts after the last column written by writeSheet
+		col := excelize.ToAlphaString(11 + j)
+		f.SetCellValue(s, col+"1", bin)
+		f.SetColWidth(s, col, col, 20)
+		for i, m := range mods {
+			if containsString(o.ModuleBinaries[moduleKey(m)], bin) {
+				f.SetCellValue(s, col+strconv.FormatInt(int64(i+2), 10), "x")
+			}
+		}
 	}
+}
 
-	return nil
+// xlsxStyles are the styles used to highlight rows by allowed state.
+type xlsxStyles struct {
+	Red, Yellow, Green int
+}
+
+// xlsxSheetName returns a valid, unique sheet name for a binary. Sheet
+// names are limited to 31 characters and can't contain some characters,
+// so this is based on the file name of the binary. names is the set of
+// lowercased sheet names already in use and is updated with the result.
+func xlsxSheetName(names map[string]struct{}, bin string) string {
+	v := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`:\/?*[]`, r) {
+			return '_'
+		}
+
+		return r
+	}, filepath.Base(bin))
+
+	result := truncateString(v, 31)
+	for i := 2; ; i++ {
+		if _, ok := names[strings.ToLower(result)]; !ok {
+			break
+		}
+
+		suffix := fmt.Sprintf(" (%d)", i)
+		result = truncateString(v, 31-len(suffix)) + suffix
+	}
+
+	names[strings.ToLower(result)] = struct{}{}
+	return result
+}
+
+// truncateString returns v truncated to at most n runes.
+func truncateString(v string, n int) string {
+	if r := []rune(v); len(r) > n {
+		return string(r[:n])
+	}
+
+	return v
+}
+
+// containsString returns true if vs contains v.
+func containsString(vs []string, v string) bool {
+	for _, s := range vs {
+		if s == v {
+			return true
+		}
+	}
+
+	return false
 }
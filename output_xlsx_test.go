@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestXLSXSheetName(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Bins     []string
+		Expected []string
+	}{
+		{
+			"base name",
+			[]string{"/usr/bin/app", "/opt/tool"},
+			[]string{"app", "tool"},
+		},
+
+		{
+			"invalid characters",
+			[]string{"image.tar:repo:tag@sha256:abc:/bin/a[1]"},
+			[]string{"a_1_"},
+		},
+
+		{
+			"duplicates",
+			[]string{"/a/app", "/b/app", "/c/APP"},
+			[]string{"app", "app (2)", "APP (3)"},
+		},
+
+		{
+			"summary",
+			[]string{"/bin/Summary", "/bin/summary", "/bin/SUMMARY"},
+			[]string{"Summary (2)", "summary (3)", "SUMMARY (4)"},
+		},
+
+		{
+			"truncated",
+			[]string{"/bin/abcdefghijklmnopqrstuvwxyz0123456789", "/sbin/abcdefghijklmnopqrstuvwxyz0123456789"},
+			[]string{"abcdefghijklmnopqrstuvwxyz01234", "abcdefghijklmnopqrstuvwxyz0 (2)"},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			names := map[string]struct{}{"summary": {}}
+			var actual []string
+			for _, bin := range tt.Bins {
+				actual = append(actual, xlsxSheetName(names, bin))
+			}
+
+			require.Equal(t, tt.Expected, actual)
+		})
+	}
+}
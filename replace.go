@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+)
+
+// lookupReplaced finds the license of a module that was replaced with a
+// replace directive using lookup.
+//
+// For a replacement with another module, such as a fork, the license of
+// the replacement is used since that is the source that was built. The
+// license of the upstream module is also looked up and a warning is added
+// if they differ. If no license is found for the replacement, the license
+// of the upstream module is used with a warning.
+//
+// A replacement with a local directory can't be looked up, so the license
+// of the upstream module is used with a warning.
+func lookupReplaced(
+	ctx context.Context,
+	m module.Module,
+	lookup func(context.Context, module.Module) (*license.License, error),
+) (*license.License, error) {
+	upstream := module.Module{Path: m.Path, Version: m.Version, Hash: m.Hash}
+	if m.LocalReplace() {
+		lic, err := lookup(ctx, upstream)
+		return withWarning(lic, fmt.Sprintf(
+			"license of local replacement unknown, using license of %s", m.Path)), err
+	}
+
+	lic, err := lookup(ctx, m.Source())
+	ulic, uerr := lookup(ctx, upstream)
+	switch {
+	case lic == nil || lic.Inconclusive():
+		if ulic == nil || uerr != nil {
+			return lic, err
+		}
+
+		return withWarning(ulic, fmt.Sprintf(
+			"no license found for replacement, using license of %s", m.Path)), uerr
+
+	case ulic != nil && uerr == nil &&
+		!sameDiffLicense(lookupResult{License: lic}, lookupResult{License: ulic}):
+		return withWarning(lic, fmt.Sprintf(
+			"license differs from upstream %s: %s", m.Path, ulic)), err
+	}
+
+	return lic, err
+}
+
+// withWarning returns a copy of l with the warning added, so cached
+// results aren't modified. If l is nil, this returns nil.
+func withWarning(l *license.License, w string) *license.License {
+	if l == nil {
+		return nil
+	}
+
+	copy := *l
+	copy.Warnings = append(append([]string(nil), l.Warnings...), w)
+	return &copy
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/mitchellh/golicense/config"
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+)
+
+// Exit codes. If several modules fail for different reasons, the exit
+// code is for the most severe reason: denied, then unknown, then lookup
+// errors. Which results fail the run is set by the fail_on setting of
+// the configuration, see summary.Fails.
+const (
+	ExitOK      = 0 // all modules passed
+	ExitDenied  = 1 // at least one module has a denied license
+	ExitInput   = 2 // the configuration or an input couldn't be read, or a report couldn't be written
+	ExitUnknown = 3 // at least one module has an unknown license or no license
+	ExitLookup  = 4 // the license lookup of at least one module failed
+)
+
+// outcome is the result of checking the license of a single module.
+type outcome int
+
+const (
+	outcomeAllowed    outcome = iota // the license is allowed
+	outcomeWarned                    // the license is in the warn list
+	outcomeUnknown                   // the license isn't in the allow or deny list
+	outcomeDenied                    // the license is denied
+	outcomeUnlicensed                // no license was found
+	outcomeError                     // the license lookup failed
+)
+
+// outcomes are all outcomes in the order they're listed in the summary.
+var outcomes = []outcome{
+	outcomeAllowed,
+	outcomeWarned,
+	outcomeUnknown,
+	outcomeDenied,
+	outcomeUnlicensed,
+	outcomeError,
+}
+
+func (o outcome) String() string {
+	switch o {
+	case outcomeAllowed:
+		return "allowed"
+	case outcomeWarned:
+		return "warned"
+	case outcomeUnknown:
+		return "unknown"
+	case outcomeDenied:
+		return "denied"
+	case outcomeUnlicensed:
+		return "no license"
+	case outcomeError:
+		return "lookup error"
+	}
+
+	return fmt.Sprintf("outcome(%d)", int(o))
+}
+
+// checkModule returns the outcome of a license lookup for a module, along
+// with the exception that applies to it, if any. cfg may be nil.
+func checkModule(cfg *config.Config, m *module.Module, l *license.License, err error) (outcome, *config.Exception) {
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+
+	state, e := cfg.ModuleAllowed(m, l)
+	switch {
+	case state == config.StateAllowed:
+		return outcomeAllowed, e
+
+	case state != config.StateDenied && cfg.Warned(l):
+		return outcomeWarned, e
+
+	case l == nil && err != nil:
+		return outcomeError, e
+
+	case l == nil:
+		return outcomeUnlicensed, e
+
+	case state == config.StateDenied:
+		return outcomeDenied, e
+	}
+
+	return outcomeUnknown, e
+}
+
+// summary counts the outcomes and licenses of the checked modules.
+type summary struct {
+	// Policy is true if the configuration has an allow or deny list. If
+	// not, unknown licenses don't fail the run since nothing is allowed.
+	Policy bool
+
+	// FailOn is the fail_on setting of the configuration. See Fails.
+	FailOn string
+
+	outcomes map[outcome]int
+	licenses map[string]int
+}
+
+// Add records the outcome of a module.
+func (s *summary) Add(o outcome, l *license.License) {
+	if s.outcomes == nil {
+		s.outcomes = make(map[outcome]int)
+		s.licenses = make(map[string]int)
+	}
+
+	s.outcomes[o]++
+	if l != nil {
+		name := l.SPDX
+		if name == "" {
+			name = l.Name
+		}
+
+		s.licenses[name]++
+	}
+}
+
+// Fails returns true if modules with the outcome fail the run.
+//
+// Denied licenses always fail the run. Unknown licenses fail it with a
+// fail_on of "unknown" or "all", and modules without a license and failed
+// lookups fail it with "all". If fail_on isn't set, everything but unknown
+// licenses fails the run, and unknown licenses fail it only if there is
+// an allow or deny list.
+func (s *summary) Fails(o outcome) bool {
+	switch o {
+	case outcomeDenied:
+		return true
+
+	case outcomeUnknown:
+		switch s.FailOn {
+		case "":
+			return s.Policy
+		case config.FailOnUnknown, config.FailOnAll:
+			return true
+		}
+
+	case outcomeUnlicensed, outcomeError:
+		return s.FailOn == "" || s.FailOn == config.FailOnAll
+	}
+
+	return false
+}
+
+// ExitCode returns the exit code for the recorded outcomes.
+func (s *summary) ExitCode() int {
+	failed := func(o outcome) bool {
+		return s.outcomes[o] > 0 && s.Fails(o)
+	}
+
+	switch {
+	case failed(outcomeDenied):
+		return ExitDenied
+
+	case failed(outcomeUnlicensed), failed(outcomeUnknown):
+		return ExitUnknown
+
+	case failed(outcomeError):
+		return ExitLookup
+	}
+
+	return ExitOK
+}
+
+// Write writes a table of the number of modules per license and per
+// outcome.
+func (s *summary) Write(w io.Writer) error {
+	names := make([]string, 0, len(s.licenses))
+	for name := range s.licenses {
+		names = append(names, name)
+	}
+
+	// Most common licenses first
+	sort.Slice(names, func(i, j int) bool {
+		a, b := names[i], names[j]
+		if s.licenses[a] != s.licenses[b] {
+			return s.licenses[a] > s.licenses[b]
+		}
+
+		return a < b
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "\nSummary:\n\n")
+	fmt.Fprintf(tw, "  LICENSE\tMODULES\n")
+	for _, name := range names {
+		fmt.Fprintf(tw, "  %s\t%d\n", name, s.licenses[name])
+	}
+
+	fmt.Fprintf(tw, "\n  RESULT\tMODULES\n")
+	for _, o := range outcomes {
+		if n := s.outcomes[o]; n > 0 {
+			fmt.Fprintf(tw, "  %s\t%d\n", o, n)
+		}
+	}
+
+	code := s.ExitCode()
+	fmt.Fprintf(tw, "\nExit code %d: %s\n", code, exitDescription(code))
+	return tw.Flush()
+}
+
+// exitDescription returns a human-friendly description of an exit code.
+func exitDescription(code int) string {
+	switch code {
+	case ExitOK:
+		return "all modules passed"
+	case ExitDenied:
+		return "denied licenses found"
+	case ExitInput:
+		return "error reading inputs or writing reports"
+	case ExitUnknown:
+		return "unknown licenses or modules without a license found"
+	case ExitLookup:
+		return "license lookups failed"
+	}
+
+	return "unknown exit code"
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mitchellh/golicense/config"
+	"github.com/mitchellh/golicense/license"
+	"github.com/mitchellh/golicense/module"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummary_ExitCode(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Outcomes []outcome
+		Expected int
+	}{
+		{"none", nil, ExitOK},
+		{"allowed", []outcome{outcomeAllowed}, ExitOK},
+		{"warned", []outcome{outcomeWarned}, ExitOK},
+		{"unknown", []outcome{outcomeUnknown}, ExitUnknown},
+		{"denied", []outcome{outcomeDenied}, ExitDenied},
+		{"unlicensed", []outcome{outcomeUnlicensed}, ExitUnknown},
+		{"lookup error", []outcome{outcomeError}, ExitLookup},
+
+		{
+			"denied before unknown",
+			[]outcome{outcomeUnknown, outcomeDenied, outcomeAllowed},
+			ExitDenied,
+		},
+
+		{
+			"denied before lookup error",
+			[]outcome{outcomeError, outcomeDenied},
+			ExitDenied,
+		},
+
+		{
+			"unknown before lookup error",
+			[]outcome{outcomeError, outcomeUnknown},
+			ExitUnknown,
+		},
+
+		{
+			"unlicensed before lookup error",
+			[]outcome{outcomeError, outcomeUnlicensed},
+			ExitUnknown,
+		},
+
+		{
+			"all",
+			outcomes,
+			ExitDenied,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			s := &summary{Policy: true}
+			for _, o := range tt.Outcomes {
+				s.Add(o, nil)
+			}
+
+			require.Equal(t, tt.Expected, s.ExitCode())
+		})
+	}
+}
+
+func TestSummary_Fails(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Policy bool
+		FailOn string
+		Fails  []outcome
+	}{
+		{
+			"default without policy",
+			false,
+			"",
+			[]outcome{outcomeDenied, outcomeUnlicensed, outcomeError},
+		},
+
+		{
+			"default with policy",
+			true,
+			"",
+			[]outcome{outcomeUnknown, outcomeDenied, outcomeUnlicensed, outcomeError},
+		},
+
+		{
+			"denied without policy",
+			false,
+			config.FailOnDenied,
+			[]outcome{outcomeDenied},
+		},
+
+		{
+			"denied with policy",
+			true,
+			config.FailOnDenied,
+			[]outcome{outcomeDenied},
+		},
+
+		{
+			"unknown without policy",
+			false,
+			config.FailOnUnknown,
+			[]outcome{outcomeUnknown, outcomeDenied},
+		},
+
+		{
+			"unknown with policy",
+			true,
+			config.FailOnUnknown,
+			[]outcome{outcomeUnknown, outcomeDenied},
+		},
+
+		{
+			"all without policy",
+			false,
+			config.FailOnAll,
+			[]outcome{outcomeUnknown, outcomeDenied, outcomeUnlicensed, outcomeError},
+		},
+
+		{
+			"all with policy",
+			true,
+			config.FailOnAll,
+			[]outcome{outcomeUnknown, outcomeDenied, outcomeUnlicensed, outcomeError},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			s := &summary{Policy: tt.Policy, FailOn: tt.FailOn}
+			var actual []outcome
+			for _, o := range outcomes {
+				if s.Fails(o) {
+					actual = append(actual, o)
+				}
+			}
+
+			require.Equal(t, tt.Fails, actual)
+		})
+	}
+}
+
+func TestCheckModule_warn(t *testing.T) {
+	cfg := &config.Config{
+		Allow: []string{"MIT"},
+		Deny:  []string{"GPL-3.0"},
+		Warn:  []string{"MIT", "GPL-3.0", "MPL-2.0"},
+	}
+
+	cases := []struct {
+		Name     string
+		License  *license.License
+		Err      error
+		Expected outcome
+	}{
+		{"allowed", &license.License{SPDX: "MIT"}, nil, outcomeAllowed},
+		{"denied", &license.License{SPDX: "GPL-3.0"}, nil, outcomeDenied},
+		{"warned", &license.License{SPDX: "MPL-2.0"}, nil, outcomeWarned},
+		{"warned expression", &license.License{SPDX: "MPL-2.0 OR Apache-2.0"}, nil, outcomeWarned},
+		{"unknown", &license.License{SPDX: "Apache-2.0"}, nil, outcomeUnknown},
+		{"unlicensed", nil, nil, outcomeUnlicensed},
+		{"lookup error", nil, errors.New("lookup failed"), outcomeError},
+	}
+
+	m := &module.Module{Path: "github.com/example/a", Version: "v1.0.0"}
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			actual, _ := checkModule(cfg, m, tt.License, tt.Err)
+			require.Equal(t, tt.Expected, actual)
+
+			// Warned licenses never fail the run.
+			if actual == outcomeWarned {
+				for _, failOn := range []string{"", config.FailOnDenied, config.FailOnUnknown, config.FailOnAll} {
+					s := &summary{Policy: true, FailOn: failOn}
+					require.False(t, s.Fails(actual), failOn)
+				}
+			}
+		})
+	}
+}